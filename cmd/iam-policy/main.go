@@ -0,0 +1,53 @@
+// Command iam-policy generates the least-privilege IAM policy, or a
+// CloudFormation template for an IAM role, needed by the given features.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+)
+
+func main() {
+	var defaults []string
+	for _, f := range core.TemplateFeatures {
+		defaults = append(defaults, string(f))
+	}
+	features := flag.String("features", strings.Join(defaults, ","),
+		"Comma separated list of features: estimate, tag-apply, native-apply, ebs-optimizer, multi-account")
+	format := flag.String("format", "policy", "Output format: policy or cloudformation")
+	output := flag.String("o", "", "Output file, defaults to stdout")
+	flag.Parse()
+
+	var selected []core.Feature
+	for _, f := range strings.Split(*features, ",") {
+		selected = append(selected, core.Feature(strings.TrimSpace(f)))
+	}
+
+	var out []byte
+	var err error
+	switch *format {
+	case "policy":
+		out, err = core.IAMPolicyDocument(selected)
+		out = append(out, '\n')
+	case "cloudformation":
+		out, err = core.CloudFormationTemplate(selected)
+	default:
+		log.Fatalf("Unknown format %q", *format)
+	}
+	if err != nil {
+		log.Fatalf("Couldn't generate the %s: %v", *format, err)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(out)
+		return
+	}
+
+	if err := os.WriteFile(*output, out, 0644); err != nil {
+		log.Fatalf("Couldn't write %s: %v", *output, err)
+	}
+}
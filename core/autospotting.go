@@ -9,6 +9,7 @@ import (
 
 	"fyne.io/fyne/v2/widget"
 	ec2instancesinfo "github.com/LeanerCloud/ec2-instances-info"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -36,6 +37,7 @@ type ASG struct {
 	ami                             string
 	spotProduct                     *string
 	Enabled                         bool
+	Selected                        bool
 	OnDemandNumber                  int64
 	OnDemandPercentage              float64
 	OnDemandPercentageEntry         *widget.Entry
@@ -44,10 +46,17 @@ type ASG struct {
 	EnabledTagExistedInitially      bool
 	ODNumberTagExistedInitially     bool
 	ODPercentageTagExistedInitially bool
+	EKSCluster                      string
+	EKSNodeGroup                    string
+	amiName                         string
+	securityGroups                  []string
 }
 
 func (a *AutoSpotting) LoadASGData() error {
 
+	// Reloading the region replaces the previously loaded ASGs.
+	a.ASGs = nil
+
 	input := &autoscaling.DescribeAutoScalingGroupsInput{}
 
 	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(a.services.autoscaling, input)
@@ -63,34 +72,10 @@ func (a *AutoSpotting) LoadASGData() error {
 				AutoScalingGroup: asg,
 				services:         a.services,
 				region:           a.region,
+				Selected:         true,
 			}
-			for _, tag := range asg.Tags {
-				switch *tag.Key {
-				case "spot-enabled":
-					{
-						asgData.Enabled, _ = strconv.ParseBool(*tag.Value)
-						asgData.EnabledTagExistedInitially = true
-					}
-				case "autospotting_min_on_demand_number":
-					{
-						asgData.OnDemandNumber, _ = strconv.ParseInt(*tag.Value, 10, 64)
-						asgData.ODNumberTagExistedInitially = true
-					}
-				case "autospotting_min_on_demand_percentage":
-					{
-						n, _ := strconv.ParseFloat(*tag.Value, 64)
-						if n > 100 {
-							n = 100
-						}
-						if n < 0 {
-							n = 0
-						}
-						asgData.OnDemandPercentage = n
-						asgData.ODPercentageTagExistedInitially = true
-					}
-
-				}
-			}
+			asgData.readTags()
+			asgData.readEKSTags()
 
 			log.Printf("%#v", asgData)
 
@@ -105,6 +90,42 @@ func (a *AutoSpotting) LoadASGData() error {
 	return nil
 }
 
+// readTags reads the AutoSpotting configuration from the ASG tags. Missing
+// tags reset the configuration to the AutoSpotting defaults.
+func (asg *ASG) readTags() {
+	asg.Enabled, asg.EnabledTagExistedInitially = false, false
+	asg.OnDemandNumber, asg.ODNumberTagExistedInitially = 0, false
+	asg.OnDemandPercentage, asg.ODPercentageTagExistedInitially = 0, false
+
+	for _, tag := range asg.Tags {
+		switch *tag.Key {
+		case tagSpotEnabled:
+			{
+				asg.Enabled, _ = strconv.ParseBool(*tag.Value)
+				asg.EnabledTagExistedInitially = true
+			}
+		case tagMinOnDemandNumber:
+			{
+				asg.OnDemandNumber, _ = strconv.ParseInt(*tag.Value, 10, 64)
+				asg.ODNumberTagExistedInitially = true
+			}
+		case tagMinOnDemandPercent:
+			{
+				n, _ := strconv.ParseFloat(*tag.Value, 64)
+				if n > 100 {
+					n = 100
+				}
+				if n < 0 {
+					n = 0
+				}
+				asg.OnDemandPercentage = n
+				asg.ODPercentageTagExistedInitially = true
+			}
+
+		}
+	}
+}
+
 func (asg *ASG) populate() {
 	asg.readASGConfiguration()
 
@@ -127,6 +148,7 @@ func (asg *ASG) readASGConfiguration() {
 		}
 		asg.InstanceTypes = []string{*resp.LaunchConfigurations[0].InstanceType}
 		asg.ami = *resp.LaunchConfigurations[0].ImageId
+		asg.securityGroups = resp.LaunchConfigurations[0].SecurityGroups
 	}
 
 	if asg.LaunchTemplate != nil {
@@ -142,6 +164,7 @@ func (asg *ASG) readASGConfiguration() {
 		for _, lt := range resp.LaunchTemplateVersions {
 			asg.InstanceTypes = []string{string(lt.LaunchTemplateData.InstanceType)}
 			asg.ami = *lt.LaunchTemplateData.ImageId
+			asg.readSecurityGroups(lt.LaunchTemplateData)
 		}
 
 	}
@@ -160,6 +183,7 @@ func (asg *ASG) readASGConfiguration() {
 		// Assuming there's at least one version returned and it's safe to access the first element
 		if len(overrideResp.LaunchTemplateVersions) > 0 {
 			asg.ami = *overrideResp.LaunchTemplateVersions[0].LaunchTemplateData.ImageId
+			asg.readSecurityGroups(overrideResp.LaunchTemplateVersions[0].LaunchTemplateData)
 			// Reset or initialize the slice to ensure it's ready for override instance types or the default instance type
 			asg.InstanceTypes = []string{}
 
@@ -231,32 +255,26 @@ func (asg *ASG) CalculateHourlyPricing() error {
 }
 
 func (asg *ASG) getHourlyPricing(figureType, instanceType, region, spotProduct string) *ec2instancesinfo.Pricing {
-	var ret ec2instancesinfo.Pricing
-
 	//log.Printf("ASG: %#v", asg)
 	//log.Printf("ASG region: %#v", asg.region)
 	//log.Printf("ASG region instance type data: %#v", asg.region.instanceTypeData)
 
-	for _, i := range *asg.region.instanceTypeData {
+	return asg.region.hourlyPricing(instanceType, region, spotProduct)
+}
+
+// hourlyPricing returns the discounted OnDemand and Spot prices of the
+// instance type for the given Spot product.
+func (r *Region) hourlyPricing(instanceType, region, spotProduct string) *ec2instancesinfo.Pricing {
+	var ret ec2instancesinfo.Pricing
+
+	for _, i := range *r.instanceTypeData {
 		if i.InstanceType != instanceType {
 			continue
 		}
 
 		//log.Printf("Found instance type, %#v with instance type information in %v %#v", i.InstanceType, region, i)
 
-		pricing := i.Pricing[region]
-
-		switch spotProduct {
-		case "Linux/UNIX":
-			ret = pricing.Linux
-		case "Windows":
-			ret = pricing.MSWin
-		case "Red Hat Enterprise Linux":
-			ret = pricing.RHEL
-		case "SUSE Linux":
-			ret = pricing.SLES
-
-		}
+		ret = r.productPricing(i.Pricing[region], instanceType, region, spotProduct)
 
 		log.Printf("Hourly pricing information: %#v", ret)
 		break
@@ -264,6 +282,29 @@ func (asg *ASG) getHourlyPricing(figureType, instanceType, region, spotProduct s
 	return &ret
 }
 
+// productPricing picks the prices of the Spot product from the regional
+// prices of an instance type, and applies the discounts.
+func (r *Region) productPricing(pricing ec2instancesinfo.RegionPrices, instanceType, region, spotProduct string) ec2instancesinfo.Pricing {
+	var ret ec2instancesinfo.Pricing
+
+	switch spotProduct {
+	case "Linux/UNIX":
+		ret = pricing.Linux
+	case "Windows":
+		ret = pricing.MSWin
+	case "Red Hat Enterprise Linux":
+		ret = pricing.RHEL
+	case "SUSE Linux":
+		ret = pricing.SLES
+
+	}
+
+	if r.Launcher != nil {
+		r.Launcher.Discounts.apply(&ret, instanceType, region)
+	}
+	return ret
+}
+
 func (asg *ASG) determineSpotProduct() (*string, error) {
 
 	resp, err := asg.services.ec2.DescribeImages(context.TODO(),
@@ -275,6 +316,7 @@ func (asg *ASG) determineSpotProduct() (*string, error) {
 		return nil, err
 	}
 	product := resp.Images[0].PlatformDetails
+	asg.amiName = aws.ToString(resp.Images[0].Name)
 
 	log.Printf("Spot Product: %s", *product)
 	return product, err
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// CurrentScenarioName is the name of the scenario holding the unsaved
+// configuration from the ASG table.
+const CurrentScenarioName = "Current configuration"
+
+// ScenarioResult is the estimate of a scenario for the loaded ASGs.
+type ScenarioResult struct {
+	Name     string
+	FeeModel FeeModel
+	Totals   Totals
+	asgs     map[string]*ASG
+}
+
+// ComparisonCell holds the figures of an ASG in a scenario over the selected
+// horizon, in the selected currency. The fee is the share of the scenario fee
+// proportional to the ASG savings.
+type ComparisonCell struct {
+	Enabled        bool
+	ProjectedCosts float64
+	Savings        float64
+	Fee            float64
+	NetSavings     float64
+}
+
+// Comparison evaluates several scenarios against the same loaded ASGs.
+type Comparison struct {
+	Results  []ScenarioResult
+	ASGNames []string
+	Horizon  Horizon
+	Currency string
+	hours    float64
+	rate     float64
+}
+
+// CurrentScenario returns the unsaved configuration of the ASG table as a
+// scenario, so it can be compared with the saved ones.
+func (c *Launcher) CurrentScenario() (*Scenario, error) {
+	return c.scenario(CurrentScenarioName)
+}
+
+// withOverrides returns a copy of the ASG using the configuration from the
+// scenario, with its costs recalculated.
+func (asg *ASG) withOverrides(s ScenarioASG) *ASG {
+	ret := *asg
+	ret.Enabled = s.Enabled
+	ret.OnDemandNumber = s.OnDemandNumber
+	ret.OnDemandPercentage = s.OnDemandPercentage
+	if err := ret.CalculateHourlyPricing(); err != nil {
+		log.Printf("Couldn't recalculate the pricing of ASG %s: %v", s.Name, err)
+	}
+	return &ret
+}
+
+// CompareScenarios evaluates the scenarios against all the ASGs loaded in the
+// current region, without changing them. ASGs missing from a scenario keep
+// their current configuration, like when loading it.
+func (c *Launcher) CompareScenarios(scenarios []*Scenario) (*Comparison, error) {
+	if len(scenarios) < 2 {
+		return nil, errors.New("select at least two scenarios to compare")
+	}
+
+	asgs := c.currentASGs()
+	if len(asgs) == 0 {
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+
+	cmp := Comparison{
+		Horizon:  c.Horizon,
+		Currency: c.CurrencyCode(),
+		hours:    c.PricingIntervalMultiplier,
+		rate:     c.ExchangeRate(),
+	}
+	for _, asg := range asgs {
+		cmp.ASGNames = append(cmp.ASGNames, *asg.AutoScalingGroupName)
+	}
+	sort.Strings(cmp.ASGNames)
+
+	for _, s := range scenarios {
+		saved := map[string]ScenarioASG{}
+		for _, a := range s.ASGs {
+			saved[a.Name] = a
+		}
+
+		fee, err := s.Settings.Fee.Model()
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee model in scenario %s: %w", s.Name, err)
+		}
+
+		result := ScenarioResult{Name: s.Name, FeeModel: fee, asgs: map[string]*ASG{}}
+		var evaluated []*ASG
+		for _, asg := range asgs {
+			e := asg
+			if a, ok := saved[*asg.AutoScalingGroupName]; ok {
+				e = asg.withOverrides(a)
+			}
+			result.asgs[*asg.AutoScalingGroupName] = e
+			evaluated = append(evaluated, e)
+		}
+		result.Totals = totals(evaluated, fee, c.PricingIntervalMultiplier).converted(cmp.rate)
+		cmp.Results = append(cmp.Results, result)
+	}
+	return &cmp, nil
+}
+
+// Cell returns the figures of an ASG in the given scenario result.
+func (cmp *Comparison) Cell(result int, name string) ComparisonCell {
+	r := cmp.Results[result]
+	asg, ok := r.asgs[name]
+	if !ok {
+		return ComparisonCell{}
+	}
+
+	multiplier := cmp.hours * cmp.rate
+	cell := ComparisonCell{
+		Enabled:        asg.Enabled,
+		ProjectedCosts: asg.HourlyCosts * multiplier,
+	}
+	if asg.Enabled {
+		cell.ProjectedCosts = asg.ProjectedCosts * multiplier
+		cell.Savings = asg.ProjectedSavings * multiplier
+	}
+	if r.Totals.SpotSavings > 0 {
+		cell.Fee = r.Totals.Fee * cell.Savings / r.Totals.SpotSavings
+	}
+	cell.NetSavings = cell.Savings - cell.Fee
+	return cell
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BaseCurrency is the currency of the AWS prices.
+const BaseCurrency = "USD"
+
+const exchangeRatesFile = "exchange-rates.json"
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ExchangeRates converts the prices from USD into other currencies. Each rate
+// is the amount of the currency for one USD, and Date is the day the rates
+// were taken from, formatted as YYYY-MM-DD.
+type ExchangeRates struct {
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// DefaultExchangeRates are used until the rates are edited or imported, and
+// are as old as the bundled pricing data.
+var DefaultExchangeRates = ExchangeRates{
+	Date: "2024-02-26",
+	Rates: map[string]float64{
+		"EUR": 0.92,
+		"GBP": 0.79,
+	},
+}
+
+func (r ExchangeRates) validate() error {
+	if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+		return fmt.Errorf("invalid exchange rates date %q, expected YYYY-MM-DD", r.Date)
+	}
+	for code, rate := range r.Rates {
+		if !currencyCode.MatchString(code) {
+			return fmt.Errorf("invalid currency code %q, expected three capital letters such as EUR", code)
+		}
+		if rate <= 0 {
+			return fmt.Errorf("the exchange rate of %s must be positive, got %v", code, rate)
+		}
+	}
+	return nil
+}
+
+// Currencies returns the base currency followed by the ones from the table,
+// sorted by code.
+func (r ExchangeRates) Currencies() []string {
+	var ret []string
+	for code := range r.Rates {
+		if code != BaseCurrency {
+			ret = append(ret, code)
+		}
+	}
+	sort.Strings(ret)
+	return append([]string{BaseCurrency}, ret...)
+}
+
+// LoadExchangeRates reads the exchange rates saved in the application data,
+// falling back to the default ones if they were never saved.
+func LoadExchangeRates() (ExchangeRates, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return DefaultExchangeRates, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, exchangeRatesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultExchangeRates, nil
+	}
+	if err != nil {
+		return DefaultExchangeRates, err
+	}
+
+	var r ExchangeRates
+	if err := json.Unmarshal(data, &r); err != nil {
+		return DefaultExchangeRates, err
+	}
+	if err := r.validate(); err != nil {
+		return DefaultExchangeRates, err
+	}
+	return r, nil
+}
+
+// SaveExchangeRates persists the exchange rates in the application data.
+func SaveExchangeRates(r ExchangeRates) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	dir, err := dataDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, exchangeRatesFile)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	log.Println("Saved the exchange rates to", path)
+	return nil
+}
+
+// ImportExchangeRates parses exchange rates in the JSON format used for saving
+// them, or from CSV lines with a currency code and a rate. In the CSV format
+// the date is given by a line starting with "date", and defaults to today.
+func ImportExchangeRates(rd io.Reader) (ExchangeRates, error) {
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return ExchangeRates{}, err
+	}
+
+	var r ExchangeRates
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		if err := json.Unmarshal(data, &r); err != nil {
+			return ExchangeRates{}, err
+		}
+	} else {
+		cr := csv.NewReader(bytes.NewReader(data))
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		records, err := cr.ReadAll()
+		if err != nil {
+			return ExchangeRates{}, err
+		}
+
+		r = ExchangeRates{Date: time.Now().Format("2006-01-02"), Rates: map[string]float64{}}
+		for i, rec := range records {
+			if len(rec) < 2 {
+				continue
+			}
+			key := strings.TrimSpace(rec[0])
+			value := strings.TrimSpace(rec[1])
+			if strings.EqualFold(key, "date") {
+				r.Date = value
+				continue
+			}
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				// Skip the header line, if any.
+				if i == 0 {
+					continue
+				}
+				return ExchangeRates{}, fmt.Errorf("invalid exchange rate on line %d: %q", i+1, value)
+			}
+			r.Rates[strings.ToUpper(key)] = rate
+		}
+	}
+
+	delete(r.Rates, BaseCurrency)
+	if len(r.Rates) == 0 {
+		return ExchangeRates{}, errors.New("no exchange rates found")
+	}
+	if err := r.validate(); err != nil {
+		return ExchangeRates{}, err
+	}
+	return r, nil
+}
+
+// SetExchangeRates replaces the exchange rate table, switching back to the
+// base currency if the selected one is no longer in the table.
+func (c *Launcher) SetExchangeRates(r ExchangeRates) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	c.ExchangeRates = r
+	if _, ok := r.Rates[c.Currency]; !ok {
+		c.Currency = BaseCurrency
+	}
+	return nil
+}
+
+// SetCurrency selects the currency of all the displayed and exported costs.
+func (c *Launcher) SetCurrency(code string) error {
+	if code == BaseCurrency || code == "" {
+		c.Currency = BaseCurrency
+		return nil
+	}
+	if _, ok := c.ExchangeRates.Rates[code]; !ok {
+		return fmt.Errorf("no exchange rate for %s", code)
+	}
+	c.Currency = code
+	return nil
+}
+
+// CurrencyCode returns the code of the selected currency.
+func (c *Launcher) CurrencyCode() string {
+	if c.Currency == "" {
+		return BaseCurrency
+	}
+	return c.Currency
+}
+
+// ExchangeRate returns the amount of the selected currency for one USD.
+func (c *Launcher) ExchangeRate() float64 {
+	if rate, ok := c.ExchangeRates.Rates[c.Currency]; ok {
+		return rate
+	}
+	return 1
+}
+
+// CostMultiplier converts the hourly USD costs into the selected interval and
+// currency, as displayed in the ASG table.
+func (c *Launcher) CostMultiplier() float64 {
+	return c.PricingIntervalMultiplier * c.ExchangeRate()
+}
+
+// converted returns the totals in the currency with the given exchange rate.
+func (t Totals) converted(rate float64) Totals {
+	t.CurrentCosts *= rate
+	t.ProjectedCosts *= rate
+	t.SpotSavings *= rate
+	t.Fee *= rate
+	t.NetSavings *= rate
+	return t
+}
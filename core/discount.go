@@ -0,0 +1,232 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ec2instancesinfo "github.com/LeanerCloud/ec2-instances-info"
+)
+
+const discountRulesFile = "discounts.json"
+
+type DiscountScope string
+
+// The scopes are listed from the least to the most specific.
+const (
+	DiscountGlobal       DiscountScope = "global"
+	DiscountRegion       DiscountScope = "region"
+	DiscountFamily       DiscountScope = "family"
+	DiscountInstanceType DiscountScope = "type"
+)
+
+var DiscountScopes = []DiscountScope{DiscountGlobal, DiscountRegion, DiscountFamily, DiscountInstanceType}
+
+type DiscountTarget string
+
+const (
+	DiscountOnDemand DiscountTarget = "ondemand"
+	DiscountSpot     DiscountTarget = "spot"
+	DiscountBoth     DiscountTarget = "both"
+)
+
+var DiscountTargets = []DiscountTarget{DiscountOnDemand, DiscountSpot, DiscountBoth}
+
+// DiscountRule reduces the list prices by a percentage, for example for an
+// EDP discount or private pricing. Match is the region, the instance family
+// such as m5, or the instance type the rule applies to, and is ignored by
+// global rules.
+type DiscountRule struct {
+	Scope     DiscountScope  `json:"scope"`
+	Match     string         `json:"match,omitempty"`
+	AppliesTo DiscountTarget `json:"applies_to"`
+	Percent   float64        `json:"percent"`
+}
+
+func (r DiscountRule) String() string {
+	prices := map[DiscountTarget]string{
+		DiscountOnDemand: "OnDemand",
+		DiscountSpot:     "Spot",
+		DiscountBoth:     "OnDemand and Spot",
+	}[r.AppliesTo]
+
+	if r.Scope == DiscountGlobal {
+		return fmt.Sprintf("%g%% off %s prices", r.Percent, prices)
+	}
+	return fmt.Sprintf("%g%% off %s prices for %s %s", r.Percent, prices, r.Scope, r.Match)
+}
+
+func (r DiscountRule) validate() error {
+	specificity := r.specificity()
+	if specificity < 0 {
+		return fmt.Errorf("unknown discount scope %q", r.Scope)
+	}
+	if specificity > 0 && strings.TrimSpace(r.Match) == "" {
+		return fmt.Errorf("the %s discount needs a %s to match", r.Scope, r.Scope)
+	}
+	switch r.AppliesTo {
+	case DiscountOnDemand, DiscountSpot, DiscountBoth:
+	default:
+		return fmt.Errorf("unknown discount target %q", r.AppliesTo)
+	}
+	if r.Percent < 0 || r.Percent > 100 {
+		return fmt.Errorf("the discount must be between 0 and 100%%, got %v", r.Percent)
+	}
+	return nil
+}
+
+// specificity returns the position of the scope in DiscountScopes, or -1 for
+// unknown scopes.
+func (r DiscountRule) specificity() int {
+	for i, s := range DiscountScopes {
+		if r.Scope == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r DiscountRule) matches(instanceType, region string) bool {
+	switch r.Scope {
+	case DiscountGlobal:
+		return true
+	case DiscountRegion:
+		return strings.EqualFold(r.Match, region)
+	case DiscountFamily:
+		family, _, _ := strings.Cut(instanceType, ".")
+		return strings.EqualFold(r.Match, family)
+	case DiscountInstanceType:
+		return strings.EqualFold(r.Match, instanceType)
+	}
+	return false
+}
+
+// DiscountRules are applied to the list prices before calculating the costs of
+// the ASGs. For each of the OnDemand and Spot prices, only the most specific
+// matching rule is applied, so for example private pricing of an instance
+// family replaces the global EDP discount.
+type DiscountRules []DiscountRule
+
+func (d DiscountRules) validate() error {
+	for i, r := range d {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("discount rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// discount returns the percentage applied to the OnDemand or Spot price of the
+// instance type in the region.
+func (d DiscountRules) discount(spot bool, instanceType, region string) float64 {
+	best, ret := -1, 0.0
+	for _, r := range d {
+		if r.AppliesTo != DiscountBoth && (r.AppliesTo == DiscountSpot) != spot {
+			continue
+		}
+		if !r.matches(instanceType, region) || r.specificity() <= best {
+			continue
+		}
+		best, ret = r.specificity(), r.Percent
+	}
+	return ret
+}
+
+func (d DiscountRules) apply(p *ec2instancesinfo.Pricing, instanceType, region string) {
+	onDemand := 1 - d.discount(false, instanceType, region)/100
+	spot := 1 - d.discount(true, instanceType, region)/100
+	p.OnDemand *= onDemand
+	p.SpotMin *= spot
+	p.SpotMax *= spot
+}
+
+// Descriptions returns the rules as text, for the exports.
+func (d DiscountRules) Descriptions() []string {
+	var ret []string
+	for _, r := range d {
+		ret = append(ret, r.String())
+	}
+	return ret
+}
+
+// LoadDiscountRules reads the discount rules saved in the application data,
+// if any.
+func LoadDiscountRules() (DiscountRules, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, discountRulesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var d DiscountRules
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SaveDiscountRules persists the discount rules in the application data.
+func SaveDiscountRules(d DiscountRules) error {
+	if err := d.validate(); err != nil {
+		return err
+	}
+
+	dir, err := dataDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, discountRulesFile)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	log.Println("Saved the discount rules to", path)
+	return nil
+}
+
+// SetDiscounts replaces the discount rules and recalculates the costs of all
+// the loaded ASGs, standalone instances and fleets.
+func (c *Launcher) SetDiscounts(d DiscountRules) error {
+	if err := d.validate(); err != nil {
+		return err
+	}
+	c.Discounts = d
+
+	for _, r := range c.Regions {
+		if r == nil {
+			continue
+		}
+		if r.AutoSpotting != nil {
+			for _, asg := range r.AutoSpotting.ASGs {
+				if err := asg.CalculateHourlyPricing(); err != nil {
+					log.Printf("Couldn't recalculate the pricing of ASG %s: %v", *asg.AutoScalingGroupName, err)
+				}
+			}
+		}
+		for _, i := range r.StandaloneInstances {
+			i.calculateCosts(r)
+		}
+		r.sortStandaloneInstances()
+		r.calculateFleetCosts()
+	}
+	return nil
+}
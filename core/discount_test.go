@@ -0,0 +1,39 @@
+package core
+
+import "testing"
+
+func TestDiscountRulesDiscount(t *testing.T) {
+	rules := DiscountRules{
+		{Scope: DiscountGlobal, AppliesTo: DiscountBoth, Percent: 5},
+		{Scope: DiscountRegion, Match: "eu-west-1", AppliesTo: DiscountOnDemand, Percent: 8},
+		{Scope: DiscountFamily, Match: "M5", AppliesTo: DiscountOnDemand, Percent: 20},
+		{Scope: DiscountInstanceType, Match: "c5.large", AppliesTo: DiscountSpot, Percent: 10},
+	}
+
+	tests := []struct {
+		spot         bool
+		instanceType string
+		region       string
+		want         float64
+	}{
+		{false, "t3.micro", "us-east-1", 5},
+		{true, "t3.micro", "us-east-1", 5},
+		{false, "t3.micro", "EU-WEST-1", 8},
+		{true, "t3.micro", "eu-west-1", 5},
+		{false, "m5.xlarge", "eu-west-1", 20},
+		{true, "m5.xlarge", "eu-west-1", 5},
+		{true, "c5.large", "us-east-1", 10},
+		{false, "c5.large", "us-east-1", 5},
+		{true, "c5.xlarge", "us-east-1", 5},
+	}
+
+	for _, tt := range tests {
+		if got := rules.discount(tt.spot, tt.instanceType, tt.region); got != tt.want {
+			t.Errorf("discount(%v, %s, %s) = %v, want %v", tt.spot, tt.instanceType, tt.region, got, tt.want)
+		}
+	}
+
+	if got := DiscountRules(nil).discount(false, "m5.large", "us-east-1"); got != 0 {
+		t.Errorf("discount without rules = %v, want 0", got)
+	}
+}
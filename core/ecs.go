@@ -0,0 +1,404 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// Capacity providers and launch type managed by Fargate.
+const (
+	fargateProvider     = "FARGATE"
+	fargateSpotProvider = "FARGATE_SPOT"
+)
+
+// ECSCapacityProvider is a capacity provider of an ECS cluster backed by an
+// AutoScaling group.
+type ECSCapacityProvider struct {
+	Cluster string
+	Name    string
+	ASGName string
+
+	region *Region
+}
+
+// ASG returns the matching AutoScaling group loaded for the region, nil when
+// it's not loaded yet or couldn't be found. It's looked up by name on every
+// call, since reloading the ASGs replaces them.
+func (p *ECSCapacityProvider) ASG() *ASG {
+	if p.region == nil || p.region.AutoSpotting == nil {
+		return nil
+	}
+	for _, asg := range p.region.AutoSpotting.ASGs {
+		if aws.ToString(asg.AutoScalingGroupName) == p.ASGName {
+			return asg
+		}
+	}
+	return nil
+}
+
+// ECSService is a service of an ECS cluster. The costs are only estimated
+// for the tasks running on Fargate, the others run on the capacity of the
+// cluster and are covered by the ASGs of its capacity providers.
+type ECSService struct {
+	Cluster      string
+	Name         string
+	Capacity     string
+	DesiredCount int32
+	VCPU         float64
+	MemoryGB     float64
+	Architecture ecstypes.CPUArchitecture
+	OS           ecstypes.OSFamily
+	// FargateShare and SpotShare are the fractions of the tasks placed on
+	// Fargate and on FARGATE_SPOT, according to the weights of the capacity
+	// provider strategy.
+	FargateShare     float64
+	SpotShare        float64
+	HourlyCosts      float64
+	ProjectedCosts   float64
+	ProjectedSavings float64
+	Note             string
+}
+
+// IsFargate returns true for the services with tasks running on Fargate.
+func (s *ECSService) IsFargate() bool {
+	return s.FargateShare > 0
+}
+
+// ECSCluster holds the capacity providers and services of an ECS cluster.
+type ECSCluster struct {
+	Name              string
+	CapacityProviders []*ECSCapacityProvider
+	Services          []*ECSService
+}
+
+// ECSTotals aggregates the Fargate costs of the ECS services.
+type ECSTotals struct {
+	CurrentCosts   float64
+	ProjectedCosts float64
+	SpotSavings    float64
+}
+
+// asgNameFromARN extracts the group name from an AutoScaling group ARN, the
+// capacity providers also accept plain names.
+func asgNameFromARN(arn string) string {
+	if i := strings.LastIndex(arn, "autoScalingGroupName/"); i >= 0 {
+		return arn[i+len("autoScalingGroupName/"):]
+	}
+	return arn
+}
+
+// parseTaskSize converts the CPU units and memory MiB of a task definition,
+// which may also be given as "1 vCPU" or "2 GB", to vCPUs and GB.
+func parseTaskSize(cpu, memory string) (float64, float64, error) {
+	parse := func(value, unit string, divisor float64) (float64, error) {
+		value = strings.TrimSpace(value)
+		if strings.HasSuffix(strings.ToLower(value), unit) {
+			return strconv.ParseFloat(strings.TrimSpace(value[:len(value)-len(unit)]), 64)
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		return n / divisor, err
+	}
+
+	vCPU, err := parse(cpu, "vcpu", 1024)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid task CPU %q", cpu)
+	}
+	memoryGB, err := parse(memory, "gb", 1024)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid task memory %q", memory)
+	}
+	return vCPU, memoryGB, nil
+}
+
+// readCapacity determines where the tasks of the service are placed, falling
+// back to the default capacity provider strategy of the cluster.
+func (s *ECSService) readCapacity(svc ecstypes.Service, defaultStrategy []ecstypes.CapacityProviderStrategyItem) {
+	strategy := svc.CapacityProviderStrategy
+	if svc.LaunchType != "" {
+		s.Capacity = string(svc.LaunchType)
+		if svc.LaunchType == ecstypes.LaunchTypeFargate {
+			s.FargateShare = 1
+		}
+		return
+	}
+	if len(strategy) == 0 {
+		strategy = defaultStrategy
+	}
+
+	var total, fargate, spot float64
+	var providers []string
+	for _, item := range strategy {
+		name := aws.ToString(item.CapacityProvider)
+		providers = append(providers, fmt.Sprintf("%s:%d", name, item.Weight))
+		total += float64(item.Weight)
+		switch name {
+		case fargateProvider:
+			fargate += float64(item.Weight)
+		case fargateSpotProvider:
+			fargate += float64(item.Weight)
+			spot += float64(item.Weight)
+		}
+	}
+	s.Capacity = strings.Join(providers, ", ")
+	if total > 0 {
+		s.FargateShare = fargate / total
+		s.SpotShare = spot / total
+	}
+}
+
+// calculateFargateCosts estimates the hourly costs of the Fargate tasks of the
+// service, before and after moving all of them to FARGATE_SPOT, which is
+// discounted by the given percentage.
+func (s *ECSService) calculateFargateCosts(region string, discountPercent float64) {
+	s.HourlyCosts, s.ProjectedCosts, s.ProjectedSavings, s.Note = 0, 0, 0, ""
+	fargateSpotDiscount := discountPercent / 100
+
+	if !s.IsFargate() {
+		s.Note = "Runs on the EC2 capacity of the cluster"
+		return
+	}
+
+	if s.VCPU == 0 || s.MemoryGB == 0 {
+		s.Note = "Couldn't determine the task size, so its costs are counted as zero"
+		return
+	}
+
+	price, ok := fargateTaskHourlyPrice(region, s.VCPU, s.MemoryGB, s.Architecture, s.OS)
+	if !ok {
+		s.Note = "No Fargate pricing available for " + region
+		return
+	}
+
+	tasks := float64(s.DesiredCount) * s.FargateShare
+	onDemandShare := 1 - s.SpotShare/s.FargateShare
+	s.HourlyCosts = tasks * price * (onDemandShare + (1-onDemandShare)*(1-fargateSpotDiscount))
+
+	switch {
+	case s.Architecture == ecstypes.CPUArchitectureArm64:
+		s.Note = "FARGATE_SPOT doesn't support ARM tasks"
+		s.ProjectedCosts = s.HourlyCosts
+	case isWindowsFamily(s.OS):
+		s.Note = "FARGATE_SPOT doesn't support Windows tasks"
+		s.ProjectedCosts = s.HourlyCosts
+	default:
+		s.ProjectedCosts = tasks * price * (1 - fargateSpotDiscount)
+	}
+	s.ProjectedSavings = s.HourlyCosts - s.ProjectedCosts
+}
+
+func (r *Region) loadECSCluster(ctx context.Context, cluster ecstypes.Cluster, taskDefinitions map[string]*ecstypes.TaskDefinition) (*ECSCluster, error) {
+	ret := ECSCluster{Name: aws.ToString(cluster.ClusterName)}
+	client := r.services.ecs
+
+	var providerNames []string
+	for _, name := range cluster.CapacityProviders {
+		if name != fargateProvider && name != fargateSpotProvider {
+			providerNames = append(providerNames, name)
+		}
+	}
+	if len(providerNames) > 0 {
+		out, err := client.DescribeCapacityProviders(ctx, &ecs.DescribeCapacityProvidersInput{
+			CapacityProviders: providerNames,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't describe the capacity providers of the ECS cluster %s: %w", ret.Name, err)
+		}
+		for _, cp := range out.CapacityProviders {
+			if cp.AutoScalingGroupProvider == nil {
+				continue
+			}
+			provider := ECSCapacityProvider{
+				Cluster: ret.Name,
+				Name:    aws.ToString(cp.Name),
+				ASGName: asgNameFromARN(aws.ToString(cp.AutoScalingGroupProvider.AutoScalingGroupArn)),
+				region:  r,
+			}
+			ret.CapacityProviders = append(ret.CapacityProviders, &provider)
+		}
+	}
+
+	var serviceARNs []string
+	paginator := ecs.NewListServicesPaginator(client, &ecs.ListServicesInput{Cluster: cluster.ClusterArn})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list the services of the ECS cluster %s: %w", ret.Name, err)
+		}
+		serviceARNs = append(serviceARNs, out.ServiceArns...)
+	}
+
+	// DescribeServices accepts up to 10 services per call.
+	for i := 0; i < len(serviceARNs); i += 10 {
+		end := i + 10
+		if end > len(serviceARNs) {
+			end = len(serviceARNs)
+		}
+		out, err := client.DescribeServices(ctx, &ecs.DescribeServicesInput{
+			Cluster:  cluster.ClusterArn,
+			Services: serviceARNs[i:end],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't describe the services of the ECS cluster %s: %w", ret.Name, err)
+		}
+
+		for _, svc := range out.Services {
+			service := ECSService{
+				Cluster:      ret.Name,
+				Name:         aws.ToString(svc.ServiceName),
+				DesiredCount: svc.DesiredCount,
+				Architecture: ecstypes.CPUArchitectureX8664,
+				OS:           ecstypes.OSFamilyLinux,
+			}
+			service.readCapacity(svc, cluster.DefaultCapacityProviderStrategy)
+
+			if service.IsFargate() {
+				arn := aws.ToString(svc.TaskDefinition)
+				td, ok := taskDefinitions[arn]
+				if !ok {
+					out, err := client.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{TaskDefinition: svc.TaskDefinition})
+					if err != nil {
+						return nil, fmt.Errorf("couldn't describe the task definition %s: %w", arn, err)
+					}
+					td = out.TaskDefinition
+					taskDefinitions[arn] = td
+				}
+
+				if service.VCPU, service.MemoryGB, err = parseTaskSize(aws.ToString(td.Cpu), aws.ToString(td.Memory)); err != nil {
+					log.Printf("Couldn't determine the task size of the ECS service %s: %s", service.Name, err.Error())
+				}
+				if td.RuntimePlatform != nil {
+					if td.RuntimePlatform.CpuArchitecture != "" {
+						service.Architecture = td.RuntimePlatform.CpuArchitecture
+					}
+					if td.RuntimePlatform.OperatingSystemFamily != "" {
+						service.OS = td.RuntimePlatform.OperatingSystemFamily
+					}
+				}
+			}
+
+			service.calculateFargateCosts(r.name, r.Launcher.FargateSpotDiscount)
+			ret.Services = append(ret.Services, &service)
+		}
+	}
+
+	sort.Slice(ret.Services, func(i, j int) bool { return ret.Services[i].Name < ret.Services[j].Name })
+	return &ret, nil
+}
+
+// LoadECSData loads the ECS clusters of the current region, along with their
+// capacity providers and services. The capacity providers are matched by name
+// with the ASGs loaded for the region.
+func (c *Launcher) LoadECSData() error {
+	r := c.Regions[c.CurrentRegion]
+	if r == nil {
+		return fmt.Errorf("not connected to the region %s", c.CurrentRegion)
+	}
+	ctx := context.TODO()
+
+	var clusterARNs []string
+	paginator := ecs.NewListClustersPaginator(r.services.ecs, &ecs.ListClustersInput{})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Println("Error listing the ECS clusters:", err)
+			return err
+		}
+		clusterARNs = append(clusterARNs, out.ClusterArns...)
+	}
+
+	var clusters []*ECSCluster
+	taskDefinitions := map[string]*ecstypes.TaskDefinition{}
+
+	// DescribeClusters accepts up to 100 clusters per call.
+	for i := 0; i < len(clusterARNs); i += 100 {
+		end := i + 100
+		if end > len(clusterARNs) {
+			end = len(clusterARNs)
+		}
+		out, err := r.services.ecs.DescribeClusters(ctx, &ecs.DescribeClustersInput{Clusters: clusterARNs[i:end]})
+		if err != nil {
+			log.Println("Error describing the ECS clusters:", err)
+			return err
+		}
+		for _, cluster := range out.Clusters {
+			loaded, err := r.loadECSCluster(ctx, cluster, taskDefinitions)
+			if err != nil {
+				log.Println(err.Error())
+				return err
+			}
+			clusters = append(clusters, loaded)
+		}
+	}
+
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+	r.ECSClusters = clusters
+	log.Printf("Loaded %d ECS clusters in %s", len(clusters), r.name)
+	return nil
+}
+
+func (c *Launcher) currentECSClusters() []*ECSCluster {
+	if c.Regions == nil || c.Regions[c.CurrentRegion] == nil {
+		return nil
+	}
+	return c.Regions[c.CurrentRegion].ECSClusters
+}
+
+// ECSCapacityProviders returns the ASG backed capacity providers of the ECS
+// clusters loaded for the current region.
+func (c *Launcher) ECSCapacityProviders() []*ECSCapacityProvider {
+	var ret []*ECSCapacityProvider
+	for _, cluster := range c.currentECSClusters() {
+		ret = append(ret, cluster.CapacityProviders...)
+	}
+	return ret
+}
+
+// ECSServices returns the services of the ECS clusters loaded for the current
+// region.
+func (c *Launcher) ECSServices() []*ECSService {
+	var ret []*ECSService
+	for _, cluster := range c.currentECSClusters() {
+		ret = append(ret, cluster.Services...)
+	}
+	return ret
+}
+
+// SetFargateSpotDiscount sets the percentage FARGATE_SPOT is assumed to be
+// cheaper than FARGATE, and recalculates the costs of the loaded services.
+func (c *Launcher) SetFargateSpotDiscount(percent float64) error {
+	if percent < 0 || percent > MaxFargateSpotDiscount {
+		return fmt.Errorf("the FARGATE_SPOT discount must be between 0 and %g%%", MaxFargateSpotDiscount)
+	}
+	c.FargateSpotDiscount = percent
+
+	for name, r := range c.Regions {
+		for _, cluster := range r.ECSClusters {
+			for _, s := range cluster.Services {
+				s.calculateFargateCosts(name, percent)
+			}
+		}
+	}
+	return nil
+}
+
+// FargateTotals returns the Fargate costs of the ECS services of the current
+// region over the selected horizon and in the selected currency. FARGATE_SPOT
+// is native to ECS, so there's no fee involved.
+func (c *Launcher) FargateTotals() ECSTotals {
+	var ret ECSTotals
+	multiplier := c.CostMultiplier()
+	for _, s := range c.ECSServices() {
+		ret.CurrentCosts += s.HourlyCosts * multiplier
+		ret.ProjectedCosts += s.ProjectedCosts * multiplier
+		ret.SpotSavings += s.ProjectedSavings * multiplier
+	}
+	return ret
+}
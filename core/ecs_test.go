@@ -0,0 +1,32 @@
+package core
+
+import "testing"
+
+func TestParseTaskSize(t *testing.T) {
+	tests := []struct {
+		cpu, memory  string
+		wantVCPU     float64
+		wantMemoryGB float64
+		wantErr      bool
+	}{
+		{"256", "512", 0.25, 0.5, false},
+		{"1024", "2048", 1, 2, false},
+		{"1 vCPU", "2 GB", 1, 2, false},
+		{" 0.5 vcpu ", "4gb", 0.5, 4, false},
+		{"", "512", 0, 0, true},
+		{"256", "", 0, 0, true},
+		{"two vCPU", "2 GB", 0, 0, true},
+		{"1024", "2 TB", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		vCPU, memoryGB, err := parseTaskSize(tt.cpu, tt.memory)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTaskSize(%q, %q) error = %v, wantErr %v", tt.cpu, tt.memory, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (vCPU != tt.wantVCPU || memoryGB != tt.wantMemoryGB) {
+			t.Errorf("parseTaskSize(%q, %q) = %v, %v, want %v, %v", tt.cpu, tt.memory, vCPU, memoryGB, tt.wantVCPU, tt.wantMemoryGB)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"sort"
+)
+
+// Tags set by EKS on the ASGs backing its managed node groups.
+const (
+	tagEKSClusterName   = "eks:cluster-name"
+	tagEKSNodeGroupName = "eks:nodegroup-name"
+)
+
+// ClusterTotals aggregates the costs of the managed node groups of an EKS
+// cluster.
+type ClusterTotals struct {
+	Cluster    string
+	NodeGroups int
+	Totals
+}
+
+func (asg *ASG) readEKSTags() {
+	asg.EKSCluster, _ = asg.tagValue(tagEKSClusterName)
+	asg.EKSNodeGroup, _ = asg.tagValue(tagEKSNodeGroupName)
+}
+
+// IsManagedNodeGroup returns true for the ASGs backing EKS managed node
+// groups, which should be moved to Spot through the capacity type of the node
+// group instead of the AutoSpotting tags.
+func (asg *ASG) IsManagedNodeGroup() bool {
+	return asg.EKSCluster != "" && asg.EKSNodeGroup != ""
+}
+
+// groupByCluster moves the managed node groups first, grouped by cluster name,
+// keeping the current order within each cluster and of the other ASGs.
+func groupByCluster(asgs []*ASG) {
+	sort.SliceStable(asgs, func(i, j int) bool {
+		a, b := asgs[i], asgs[j]
+		if a.IsManagedNodeGroup() != b.IsManagedNodeGroup() {
+			return a.IsManagedNodeGroup()
+		}
+		if !a.IsManagedNodeGroup() {
+			return false
+		}
+		return a.EKSCluster < b.EKSCluster
+	})
+}
+
+// ClusterTotals returns the totals of the managed node groups matching the
+// current filter for each EKS cluster, sorted by cluster name.
+func (c *Launcher) ClusterTotals() []ClusterTotals {
+	clusters := map[string][]*ASG{}
+	var names []string
+	for _, asg := range c.VisibleASGs() {
+		if !asg.IsManagedNodeGroup() {
+			continue
+		}
+		if _, ok := clusters[asg.EKSCluster]; !ok {
+			names = append(names, asg.EKSCluster)
+		}
+		clusters[asg.EKSCluster] = append(clusters[asg.EKSCluster], asg)
+	}
+	sort.Strings(names)
+
+	var ret []ClusterTotals
+	for _, name := range names {
+		ret = append(ret, ClusterTotals{
+			Cluster:    name,
+			NodeGroups: len(clusters[name]),
+			Totals:     c.AutoSpottingTotals(clusters[name]),
+		})
+	}
+	return ret
+}
+
+// ManagedNodeGroups returns the ASGs from the plan backing EKS managed node
+// groups that would get AutoSpotting tags added or changed.
+func (p *TagPlan) ManagedNodeGroups() []*ASG {
+	var ret []*ASG
+	for _, asgPlan := range p.ASGs {
+		if asgPlan.ASG.IsManagedNodeGroup() && len(asgPlan.Pending()) > 0 {
+			ret = append(ret, asgPlan.ASG)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,300 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// EstimateSchemaVersion is the version of the JSON export format, increased
+// on incompatible changes.
+const EstimateSchemaVersion = 4
+
+type ExportFormat string
+
+const (
+	ExportCSV      ExportFormat = "csv"
+	ExportJSON     ExportFormat = "json"
+	ExportMarkdown ExportFormat = "md"
+	ExportHTML     ExportFormat = "html"
+	ExportPDF      ExportFormat = "pdf"
+)
+
+type EstimateMetadata struct {
+	AccountID       string        `json:"account_id"`
+	AccountAlias    string        `json:"account_alias,omitempty"`
+	Regions         []string      `json:"regions"`
+	PricingSource   PricingSource `json:"pricing_source"`
+	PricingAgeDays  int           `json:"pricing_age_days"`
+	GeneratedAt     time.Time     `json:"generated_at"`
+	PricingInterval string        `json:"pricing_interval"`
+	HorizonHours    float64       `json:"horizon_hours"`
+	// Currency of all the costs and savings, converted from USD using the
+	// exchange rate from the given date.
+	Currency          string  `json:"currency"`
+	ExchangeRate      float64 `json:"exchange_rate"`
+	ExchangeRatesDate string  `json:"exchange_rates_date,omitempty"`
+}
+
+// EstimateAssumptions documents how the estimate was calculated.
+type EstimateAssumptions struct {
+	FeeModel      string        `json:"fee_model"`
+	HoursPerMonth float64       `json:"hours_per_month"`
+	SpotPricing   string        `json:"spot_pricing"`
+	Discounts     DiscountRules `json:"discounts"`
+}
+
+// ASGEstimate is a row of the ASG table. Costs and savings are given in the
+// pricing interval and currency from the metadata, like the totals.
+type ASGEstimate struct {
+	Name               string   `json:"name"`
+	Region             string   `json:"region"`
+	InstanceTypes      []string `json:"instance_types"`
+	Instances          int32    `json:"instances"`
+	Costs              float64  `json:"costs"`
+	ProjectedCosts     float64  `json:"projected_costs"`
+	Savings            float64  `json:"savings"`
+	SavingsPercent     float64  `json:"savings_percent"`
+	OnDemandPercentage float64  `json:"on_demand_percentage"`
+	OnDemandNumber     int64    `json:"on_demand_number"`
+	Enabled            bool     `json:"enabled"`
+	EKSCluster         string   `json:"eks_cluster,omitempty"`
+	EKSNodeGroup       string   `json:"eks_nodegroup,omitempty"`
+}
+
+// Estimate is the exportable snapshot of the savings estimate for the
+// selected ASGs.
+type Estimate struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Metadata      EstimateMetadata    `json:"metadata"`
+	ASGs          []ASGEstimate       `json:"asgs"`
+	Totals        Totals              `json:"totals"`
+	Assumptions   EstimateAssumptions `json:"assumptions"`
+}
+
+// Estimate builds the estimate of the selected ASGs from the current region,
+// in the order they're displayed.
+func (c *Launcher) Estimate() (*Estimate, error) {
+	if c.Identity == nil {
+		return nil, errors.New("missing credentials")
+	}
+
+	var asgs []*ASG
+	for _, asg := range c.currentASGs() {
+		if asg.Selected {
+			asgs = append(asgs, asg)
+		}
+	}
+	if len(asgs) == 0 {
+		return nil, errors.New("no AutoScaling groups selected")
+	}
+	c.ASGSort.apply(asgs)
+
+	now := time.Now().UTC()
+	source := pricingSource()
+
+	e := Estimate{
+		SchemaVersion: EstimateSchemaVersion,
+		Metadata: EstimateMetadata{
+			AccountID:       c.Identity.AccountID,
+			AccountAlias:    c.Identity.Alias,
+			Regions:         []string{c.CurrentRegion},
+			PricingSource:   source,
+			PricingAgeDays:  source.AgeDays(now),
+			GeneratedAt:     now,
+			PricingInterval: c.PricingInterval(),
+			HorizonHours:    c.PricingIntervalMultiplier,
+			Currency:        c.CurrencyCode(),
+			ExchangeRate:    c.ExchangeRate(),
+		},
+		Totals: c.AutoSpottingTotals(asgs),
+		Assumptions: EstimateAssumptions{
+			FeeModel:      c.feeModel().Description(),
+			HoursPerMonth: hoursPerMonth,
+			SpotPricing:   "lowest Spot price of the instance type in the region",
+			Discounts:     append(DiscountRules{}, c.Discounts...),
+		},
+	}
+
+	if e.Metadata.Currency != BaseCurrency {
+		e.Metadata.ExchangeRatesDate = c.ExchangeRates.Date
+	}
+
+	multiplier := c.CostMultiplier()
+	for _, asg := range asgs {
+		e.ASGs = append(e.ASGs, ASGEstimate{
+			Name:               *asg.AutoScalingGroupName,
+			Region:             asg.region.name,
+			InstanceTypes:      asg.InstanceTypes,
+			Instances:          *asg.DesiredCapacity,
+			Costs:              asg.HourlyCosts * multiplier,
+			ProjectedCosts:     asg.ProjectedCosts * multiplier,
+			Savings:            asg.ProjectedSavings * multiplier,
+			SavingsPercent:     asg.ProjectedSavingsPercent(),
+			OnDemandPercentage: asg.OnDemandPercentage,
+			OnDemandNumber:     asg.OnDemandNumber,
+			Enabled:            asg.Enabled,
+			EKSCluster:         asg.EKSCluster,
+			EKSNodeGroup:       asg.EKSNodeGroup,
+		})
+	}
+	return &e, nil
+}
+
+// FileName returns the suggested file name for exporting the estimate.
+func (e *Estimate) FileName(format ExportFormat) string {
+	return fmt.Sprintf("savings-estimate-%s-%s-%s.%s", e.Metadata.AccountID,
+		strings.Join(e.Metadata.Regions, "_"), e.Metadata.GeneratedAt.Format("20060102T150405Z"), format)
+}
+
+// Export writes the estimate in the given format.
+func (e *Estimate) Export(w io.Writer, format ExportFormat) error {
+	switch format {
+	case ExportCSV:
+		return e.writeCSV(w)
+	case ExportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	case ExportMarkdown:
+		return e.writeMarkdown(w)
+	case ExportHTML:
+		return e.writeHTMLReport(w)
+	case ExportPDF:
+		return e.writePDFReport(w)
+	}
+	return fmt.Errorf("unsupported export format %q", format)
+}
+
+func (e *Estimate) metadataRows() [][]string {
+	pricingAge := "unknown"
+	if e.Metadata.PricingAgeDays >= 0 {
+		pricingAge = fmt.Sprintf("%d days", e.Metadata.PricingAgeDays)
+	}
+
+	account := e.Metadata.AccountID
+	if e.Metadata.AccountAlias != "" {
+		account += " (" + e.Metadata.AccountAlias + ")"
+	}
+
+	return [][]string{
+		{"Account", account},
+		{"Regions", strings.Join(e.Metadata.Regions, ", ")},
+		{"Pricing source", e.Metadata.PricingSource.String()},
+		{"Pricing age", pricingAge},
+		{"Generated at", e.Metadata.GeneratedAt.Format(time.RFC3339)},
+		{"Pricing interval", e.Metadata.PricingInterval},
+		{"Currency", e.currencyNote()},
+		{"Discounts", e.discountNote()},
+	}
+}
+
+func (e *Estimate) discountNote() string {
+	if len(e.Assumptions.Discounts) == 0 {
+		return "none, list prices"
+	}
+	return strings.Join(e.Assumptions.Discounts.Descriptions(), "; ")
+}
+
+// currencyNote describes the currency of the estimate and how it was
+// converted from USD.
+func (e *Estimate) currencyNote() string {
+	m := e.Metadata
+	if m.Currency == BaseCurrency || m.Currency == "" {
+		return BaseCurrency
+	}
+	return fmt.Sprintf("%s, converted at 1 %s = %g %s, rates from %s",
+		m.Currency, BaseCurrency, m.ExchangeRate, m.Currency, m.ExchangeRatesDate)
+}
+
+func (e *Estimate) asgHeader() []string {
+	interval, currency := e.Metadata.PricingInterval, " ("+e.Metadata.Currency+")"
+	return []string{
+		"AutoScaling group", "Region", "Instance types", "Instances",
+		"Current " + interval + " costs" + currency, "Projected " + interval + " costs" + currency,
+		"Projected " + interval + " savings" + currency, "Savings %",
+		"OnDemand %", "OnDemand #", "Convert to Spot",
+	}
+}
+
+func (a ASGEstimate) row() []string {
+	return []string{
+		a.Name, a.Region, strings.Join(a.InstanceTypes, " "), fmt.Sprintf("%d", a.Instances),
+		fmt.Sprintf("%.2f", a.Costs), fmt.Sprintf("%.2f", a.ProjectedCosts),
+		fmt.Sprintf("%.2f", a.Savings), fmt.Sprintf("%.1f", a.SavingsPercent),
+		fmt.Sprintf("%.2f", a.OnDemandPercentage), fmt.Sprintf("%d", a.OnDemandNumber),
+		fmt.Sprintf("%t", a.Enabled),
+	}
+}
+
+func (e *Estimate) totalRows() [][]string {
+	t, interval, currency := e.Totals, e.Metadata.PricingInterval, " ("+e.Metadata.Currency+")"
+	return [][]string{
+		{"Total current " + interval + " costs" + currency, fmt.Sprintf("%.2f", t.CurrentCosts)},
+		{"Total projected " + interval + " costs" + currency, fmt.Sprintf("%.2f", t.ProjectedCosts)},
+		{"Total projected Spot " + interval + " savings" + currency, fmt.Sprintf("%.2f", t.SpotSavings)},
+		{"Total projected Spot savings percentage", fmt.Sprintf("%.1f", t.SpotSavingsPercent)},
+		{"Fee" + currency, fmt.Sprintf("%.2f", t.Fee)},
+		{"Total " + interval + " net savings" + currency, fmt.Sprintf("%.2f", t.NetSavings)},
+	}
+}
+
+// writeCSV writes the metadata, the ASG table and the totals as separate
+// blocks, which spreadsheets import as a single sheet.
+func (e *Estimate) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	records := e.metadataRows()
+	records = append(records, nil, e.asgHeader())
+	for _, a := range e.ASGs {
+		records = append(records, a.row())
+	}
+	records = append(records, nil)
+	records = append(records, e.totalRows()...)
+
+	for _, r := range records {
+		if err := cw.Write(r); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func markdownRow(cells []string) string {
+	for i, cell := range cells {
+		cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
+
+func markdownTable(header []string, rows [][]string) string {
+	var b strings.Builder
+	b.WriteString(markdownRow(header))
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	b.WriteString(markdownRow(separator))
+	for _, r := range rows {
+		b.WriteString(markdownRow(r))
+	}
+	return b.String()
+}
+
+func (e *Estimate) writeMarkdown(w io.Writer) error {
+	var rows [][]string
+	for _, a := range e.ASGs {
+		rows = append(rows, a.row())
+	}
+
+	_, err := fmt.Fprintf(w, "# Savings estimate\n\n%s\n## AutoScaling groups\n\n%s\n## Totals\n\n%s",
+		markdownTable([]string{"Field", "Value"}, e.metadataRows()),
+		markdownTable(e.asgHeader(), rows),
+		markdownTable([]string{"Metric", "Value"}, e.totalRows()))
+	return err
+}
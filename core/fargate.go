@@ -0,0 +1,72 @@
+package core
+
+import (
+	"strings"
+
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// FargatePricingDate is when the bundled Fargate prices were last updated.
+// They're the public on-demand Linux/x86 rates, since the instance type data
+// doesn't cover Fargate.
+const FargatePricingDate = "2024-02-26"
+
+// DefaultFargateSpotDiscount is the percentage FARGATE_SPOT is assumed to be
+// cheaper than FARGATE. AWS advertises discounts of up to 70%, so the default
+// stays below that to avoid overstating the savings.
+const DefaultFargateSpotDiscount = 50.0
+
+// MaxFargateSpotDiscount is the discount advertised by AWS for FARGATE_SPOT,
+// which the estimated savings can't exceed.
+const MaxFargateSpotDiscount = 70.0
+
+// Fargate rates relative to Linux/x86 tasks.
+const (
+	fargateARMMultiplier        = 0.8
+	fargateWindowsLicensePerCPU = 0.046
+)
+
+type fargateRate struct {
+	vCPUHour float64
+	gbHour   float64
+}
+
+var fargatePricing = map[string]fargateRate{
+	"ap-northeast-1": {0.05056, 0.00553},
+	"ap-northeast-2": {0.04656, 0.00511},
+	"ap-south-1":     {0.04256, 0.004655},
+	"ap-southeast-1": {0.05056, 0.00553},
+	"ap-southeast-2": {0.04856, 0.00532},
+	"ca-central-1":   {0.04456, 0.004865},
+	"eu-central-1":   {0.04656, 0.00511},
+	"eu-north-1":     {0.04445, 0.004865},
+	"eu-west-1":      {0.04048, 0.004445},
+	"eu-west-2":      {0.04656, 0.00511},
+	"eu-west-3":      {0.04656, 0.00511},
+	"sa-east-1":      {0.0696, 0.0076},
+	"us-east-1":      {0.04048, 0.004445},
+	"us-east-2":      {0.04048, 0.004445},
+	"us-west-1":      {0.04656, 0.00511},
+	"us-west-2":      {0.04048, 0.004445},
+}
+
+// fargateTaskHourlyPrice returns the hourly on-demand price of a Fargate task,
+// or false if we have no pricing for the region.
+func fargateTaskHourlyPrice(region string, vCPU, memoryGB float64, arch ecstypes.CPUArchitecture, os ecstypes.OSFamily) (float64, bool) {
+	rate, ok := fargatePricing[region]
+	if !ok {
+		return 0, false
+	}
+	price := vCPU*rate.vCPUHour + memoryGB*rate.gbHour
+	if arch == ecstypes.CPUArchitectureArm64 {
+		price *= fargateARMMultiplier
+	}
+	if isWindowsFamily(os) {
+		price += vCPU * fargateWindowsLicensePerCPU
+	}
+	return price, true
+}
+
+func isWindowsFamily(os ecstypes.OSFamily) bool {
+	return strings.HasPrefix(string(os), "WINDOWS")
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+	"math"
+)
+
+// FeeModel calculates the fee charged for achieving the Spot savings, so the
+// net savings can be compared between AutoSpotting and its alternatives.
+type FeeModel interface {
+	// Label is displayed next to the fee in the totals.
+	Label() string
+	// Description documents the fee model in the reports.
+	Description() string
+	// MonthlyFee returns the fee for the given monthly savings.
+	MonthlyFee(monthlySavings float64) float64
+}
+
+type FeeModelType string
+
+const (
+	FeeModelAutoSpotting FeeModelType = "autospotting"
+	FeeModelPercentage   FeeModelType = "percentage"
+	FeeModelFlat         FeeModelType = "flat"
+	FeeModelNone         FeeModelType = "none"
+)
+
+var FeeModelTypes = []FeeModelType{FeeModelAutoSpotting, FeeModelPercentage, FeeModelFlat, FeeModelNone}
+
+// FeeConfig is the persisted configuration of a fee model. Value is the
+// percentage of the savings or the monthly amount, depending on the type.
+type FeeConfig struct {
+	Type  FeeModelType `json:"type"`
+	Value float64      `json:"value,omitempty"`
+}
+
+// Model returns the fee model for the configuration, defaulting to the
+// AutoSpotting one.
+func (f FeeConfig) Model() (FeeModel, error) {
+	switch f.Type {
+	case FeeModelAutoSpotting, "":
+		return autoSpottingFee{}, nil
+	case FeeModelPercentage:
+		if f.Value < 0 || f.Value > 100 {
+			return nil, fmt.Errorf("the fee percentage must be between 0 and 100, got %v", f.Value)
+		}
+		return percentageFee(f.Value), nil
+	case FeeModelFlat:
+		if f.Value < 0 {
+			return nil, fmt.Errorf("the monthly fee can't be negative, got %v", f.Value)
+		}
+		return flatFee(f.Value), nil
+	case FeeModelNone:
+		return noFee{}, nil
+	}
+	return nil, fmt.Errorf("unknown fee model %q", f.Type)
+}
+
+// autoSpottingFee follows the AWS Marketplace pricing of AutoSpotting, which
+// charges $0.73 for every full $7.30 of monthly savings.
+type autoSpottingFee struct{}
+
+func (autoSpottingFee) Label() string {
+	return "AutoSpotting charges (~10% of savings)"
+}
+
+func (autoSpottingFee) Description() string {
+	return "AutoSpotting from the AWS Marketplace, $0.73 for every $7.30 of monthly savings"
+}
+
+func (autoSpottingFee) MonthlyFee(monthlySavings float64) float64 {
+	return math.Floor(monthlySavings/7.3) * 0.73
+}
+
+type percentageFee float64
+
+func (p percentageFee) Label() string {
+	return fmt.Sprintf("Fee (%g%% of savings)", float64(p))
+}
+
+func (p percentageFee) Description() string {
+	return fmt.Sprintf("%g%% of the monthly savings", float64(p))
+}
+
+func (p percentageFee) MonthlyFee(monthlySavings float64) float64 {
+	return monthlySavings * float64(p) / 100
+}
+
+// flatFee is charged whenever there are any savings, regardless of their
+// amount.
+type flatFee float64
+
+func (f flatFee) Label() string {
+	return fmt.Sprintf("Flat monthly fee (%.2f USD)", float64(f))
+}
+
+func (f flatFee) Description() string {
+	return fmt.Sprintf("flat fee of %.2f USD per month", float64(f))
+}
+
+func (f flatFee) MonthlyFee(monthlySavings float64) float64 {
+	if monthlySavings <= 0 {
+		return 0
+	}
+	return float64(f)
+}
+
+// noFee is used for comparing with native ASG Spot support, such as a
+// MixedInstancesPolicy, which is free of charge.
+type noFee struct{}
+
+func (noFee) Label() string {
+	return "Fee (none, native ASG Spot)"
+}
+
+func (noFee) Description() string {
+	return "no fee, using the native Spot support of AutoScaling groups"
+}
+
+func (noFee) MonthlyFee(float64) float64 {
+	return 0
+}
+
+// SetFeeModel selects the fee model used for calculating the net savings.
+func (c *Launcher) SetFeeModel(f FeeConfig) error {
+	model, err := f.Model()
+	if err != nil {
+		return err
+	}
+	c.Fee = f
+	if c.FeeLabel != nil {
+		c.FeeLabel.Set(model.Label())
+	}
+	return nil
+}
+
+func (c *Launcher) feeModel() FeeModel {
+	model, err := c.Fee.Model()
+	if err != nil {
+		return autoSpottingFee{}
+	}
+	return model
+}
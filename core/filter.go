@@ -0,0 +1,199 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ASGFilterHelp describes the filter syntax, for displaying it in the UI.
+const ASGFilterHelp = `Space separated conditions, all of which need to match:
+
+  web            name contains "web" (case insensitive)
+  /^web-\d+$/    name matches the regular expression
+  env=prod       has the tag "env" with the value "prod"
+  env!=prod      doesn't have the tag "env" with the value "prod"
+  cost>5         numeric condition, supports <, <=, >, >=, = and !=
+  env="my prod"  double quotes keep spaces, and \" escapes a quote inside them
+
+Numeric conditions are available for: instances, cost, projected, savings,
+savings%, od% and od#. Costs are compared in the selected pricing interval and currency.`
+
+var numericCondition = regexp.MustCompile(`^(instances|cost|projected|savings%|savings|od%|od#)(<=|>=|!=|<|>|=)(-?[0-9]+(?:\.[0-9]+)?)$`)
+
+type filterTerm func(asg *ASG, multiplier float64) bool
+
+// ASGFilter matches ASGs against a filter expression, as described in
+// ASGFilterHelp.
+type ASGFilter struct {
+	Expression string
+	terms      []filterTerm
+}
+
+// ParseASGFilter parses a filter expression. An empty expression matches all
+// the ASGs.
+func ParseASGFilter(expression string) (*ASGFilter, error) {
+	f := ASGFilter{Expression: expression}
+
+	tokens, err := splitFilterTokens(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		term, err := parseFilterTerm(token)
+		if err != nil {
+			return nil, err
+		}
+		f.terms = append(f.terms, term)
+	}
+	return &f, nil
+}
+
+// splitFilterTokens splits the expression on whitespace, except inside double
+// quotes. Quotes can appear anywhere in a condition, such as env="my prod",
+// and are removed. Within quotes, \" and \\ are unescaped and any other
+// backslash is kept for the regular expressions.
+func splitFilterTokens(expression string) ([]string, error) {
+	var tokens []string
+	var token strings.Builder
+	inToken, quoted := false, false
+
+	runes := []rune(expression)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quoted && r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+			i++
+			token.WriteRune(runes[i])
+		case r == '"':
+			quoted = !quoted
+			inToken = true
+		case !quoted && unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, token.String())
+				token.Reset()
+				inToken = false
+			}
+		default:
+			token.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %s", expression)
+	}
+	if inToken {
+		tokens = append(tokens, token.String())
+	}
+	return tokens, nil
+}
+
+func parseFilterTerm(token string) (filterTerm, error) {
+	if len(token) > 1 && strings.HasPrefix(token, "/") && strings.HasSuffix(token, "/") {
+		re, err := regexp.Compile(token[1 : len(token)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %s: %w", token, err)
+		}
+		return func(asg *ASG, _ float64) bool {
+			return re.MatchString(*asg.AutoScalingGroupName)
+		}, nil
+	}
+
+	if m := numericCondition.FindStringSubmatch(token); m != nil {
+		metric, op := m[1], m[2]
+		value, _ := strconv.ParseFloat(m[3], 64)
+		return func(asg *ASG, multiplier float64) bool {
+			return compareCondition(asgMetric(asg, metric, multiplier), op, value)
+		}, nil
+	}
+
+	if strings.HasPrefix(token, "/") {
+		return nil, fmt.Errorf("unterminated regular expression %s", token)
+	}
+
+	if key, value, ok := strings.Cut(token, "!="); ok {
+		if key == "" {
+			return nil, fmt.Errorf("missing tag key in %s", token)
+		}
+		return func(asg *ASG, _ float64) bool {
+			v, exists := asg.tagValue(key)
+			return !exists || v != value
+		}, nil
+	}
+
+	if key, value, ok := strings.Cut(token, "="); ok {
+		if key == "" {
+			return nil, fmt.Errorf("missing tag key in %s", token)
+		}
+		return func(asg *ASG, _ float64) bool {
+			v, exists := asg.tagValue(key)
+			return exists && v == value
+		}, nil
+	}
+
+	if strings.ContainsAny(token, "<>") {
+		return nil, fmt.Errorf("invalid numeric condition %s", token)
+	}
+
+	name := strings.ToLower(token)
+	return func(asg *ASG, _ float64) bool {
+		return strings.Contains(strings.ToLower(*asg.AutoScalingGroupName), name)
+	}, nil
+}
+
+func asgMetric(asg *ASG, metric string, multiplier float64) float64 {
+	switch metric {
+	case "instances":
+		return float64(*asg.DesiredCapacity)
+	case "cost":
+		return asg.HourlyCosts * multiplier
+	case "projected":
+		return asg.ProjectedCosts * multiplier
+	case "savings":
+		return asg.ProjectedSavings * multiplier
+	case "savings%":
+		return asg.ProjectedSavingsPercent()
+	case "od%":
+		return asg.OnDemandPercentage
+	case "od#":
+		return float64(asg.OnDemandNumber)
+	}
+	return 0
+}
+
+func compareCondition(a float64, op string, b float64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	}
+	return false
+}
+
+// Match returns true if the ASG matches all the conditions of the filter.
+// The multiplier converts the hourly costs into the displayed interval and
+// currency.
+func (f *ASGFilter) Match(asg *ASG, multiplier float64) bool {
+	if f == nil {
+		return true
+	}
+	for _, term := range f.terms {
+		if !term(asg, multiplier) {
+			return false
+		}
+	}
+	return true
+}
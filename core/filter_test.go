@@ -0,0 +1,101 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+func testASG(name string, tags map[string]string) *ASG {
+	asg := &ASG{
+		AutoScalingGroup: types.AutoScalingGroup{
+			AutoScalingGroupName: aws.String(name),
+			DesiredCapacity:      aws.Int32(4),
+		},
+		HourlyCosts:        2,
+		ProjectedCosts:     1,
+		ProjectedSavings:   1,
+		OnDemandPercentage: 25,
+	}
+	for k, v := range tags {
+		asg.setTagValue(k, v)
+	}
+	return asg
+}
+
+func TestSplitFilterTokens(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       []string
+		wantErr    bool
+	}{
+		{"", nil, false},
+		{"  web   env=prod ", []string{"web", "env=prod"}, false},
+		{`env="my prod" web`, []string{"env=my prod", "web"}, false},
+		{`"/web app/"`, []string{"/web app/"}, false},
+		{`"say \"hi\"" x`, []string{`say "hi"`, "x"}, false},
+		{`"/^web-\d+$/"`, []string{`/^web-\d+$/`}, false},
+		{`env=""`, []string{"env="}, false},
+		{`env="prod`, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitFilterTokens(tt.expression)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitFilterTokens(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitFilterTokens(%q) = %q, want %q", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestParseASGFilter(t *testing.T) {
+	web := testASG("web-prod-1", map[string]string{"env": "prod", "team": "big data"})
+	batch := testASG("Batch", map[string]string{"env": "dev"})
+
+	tests := []struct {
+		expression string
+		want       []bool // matches for web and batch
+		wantErr    bool
+	}{
+		{"", []bool{true, true}, false},
+		{"WEB", []bool{true, false}, false},
+		{`/^web-\d+$/`, []bool{false, false}, false},
+		{`/^web-prod-\d+$/`, []bool{true, false}, false},
+		{"env=prod", []bool{true, false}, false},
+		{"env!=prod", []bool{false, true}, false},
+		{"team!=x", []bool{true, true}, false},
+		{`team="big data"`, []bool{true, false}, false},
+		{"team=big data", []bool{false, false}, false},
+		{"cost>=2 instances=4", []bool{true, true}, false},
+		{"od%<20", []bool{false, false}, false},
+		{"savings%=50", []bool{true, true}, false},
+		{"web env=dev", []bool{false, false}, false},
+		{"=prod", nil, true},
+		{"!=prod", nil, true},
+		{"/web", nil, true},
+		{"/[/", nil, true},
+		{"cost>x", nil, true},
+		{`env="prod`, nil, true},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseASGFilter(tt.expression)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseASGFilter(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		for i, asg := range []*ASG{web, batch} {
+			if got := f.Match(asg, 1); got != tt.want[i] {
+				t.Errorf("ParseASGFilter(%q).Match(%s) = %v, want %v", tt.expression, *asg.AutoScalingGroupName, got, tt.want[i])
+			}
+		}
+	}
+}
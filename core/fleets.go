@@ -0,0 +1,434 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Kinds of fleets.
+const (
+	FleetKindSpotFleet = "Spot Fleet"
+	FleetKindEC2Fleet  = "EC2 Fleet"
+)
+
+// maxSuggestedPools limits the instance types suggested for widening the
+// instance pools of a fleet.
+const maxSuggestedPools = 3
+
+// Fleet is an active Spot Fleet request or EC2 Fleet, along with the
+// instances it launched.
+type Fleet struct {
+	ID                     string
+	Kind                   string
+	Type                   string
+	AllocationStrategy     string
+	TargetCapacity         int32
+	OnDemandTargetCapacity int32
+	// InstancePools are the instance types from the launch specifications and
+	// overrides of the fleet. Launch templates without overrides fall back to
+	// the instance types of the running instances.
+	InstancePools     []string
+	OnDemandInstances int
+	SpotInstances     int
+	// OnDemandCapacity and SpotCapacity are the running capacity in the units
+	// of the target capacity, using the weights of the instance types.
+	OnDemandCapacity float64
+	SpotCapacity     float64
+	HourlyCosts      float64
+	// PoolSavings are the hourly savings of running the Spot instances on the
+	// cheapest of the SuggestedPools, which have the same number of vCPUs and
+	// at least as much memory.
+	PoolSavings    float64
+	SuggestedPools []string
+
+	// onDemandSavings are the hourly savings of running each OnDemand
+	// instance on Spot, from the largest to the smallest.
+	onDemandSavings []onDemandSavings
+	// instances are the running instances of the fleet, kept for repricing
+	// them when the discounts change.
+	instances []ec2types.Instance
+	// weights are the weighted capacities of the instance types, which
+	// default to 1.
+	weights map[string]float64
+	// poolsFromInstances is set when a launch template has no overrides, so
+	// the running instances show which types it launches.
+	poolsFromInstances bool
+}
+
+type onDemandSavings struct {
+	savings float64
+	weight  float64
+}
+
+// FleetTotals aggregates the costs of the fleets.
+type FleetTotals struct {
+	Fleets            int
+	OnDemandInstances int
+	SpotInstances     int
+	CurrentCosts      float64
+	SpotShareSavings  float64
+	PoolSavings       float64
+}
+
+// SpotSharePercent returns the percentage of the running capacity of the
+// fleet that is on Spot, in the weighted units of the target capacity.
+func (f *Fleet) SpotSharePercent() float64 {
+	total := f.OnDemandCapacity + f.SpotCapacity
+	if total == 0 {
+		return 0
+	}
+	return f.SpotCapacity / total * 100
+}
+
+// SpotShareSavings returns the hourly savings of moving OnDemand instances
+// to Spot until the given percentage of the running capacity is on Spot. The
+// OnDemand instances with the largest savings are moved first.
+func (f *Fleet) SpotShareSavings(targetPercent float64) float64 {
+	missing := (f.OnDemandCapacity+f.SpotCapacity)*targetPercent/100 - f.SpotCapacity
+	var ret float64
+	for _, s := range f.onDemandSavings {
+		if missing <= 0 {
+			break
+		}
+		ret += s.savings
+		missing -= s.weight
+	}
+	return ret
+}
+
+// weight returns the weighted capacity of the instance type.
+func (f *Fleet) weight(instanceType string) float64 {
+	if w, ok := f.weights[instanceType]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+func (f *Fleet) setWeight(instanceType ec2types.InstanceType, weight *float64) {
+	if instanceType == "" || weight == nil {
+		return
+	}
+	if f.weights == nil {
+		f.weights = map[string]float64{}
+	}
+	f.weights[string(instanceType)] = *weight
+}
+
+func (f *Fleet) hasPool(instanceType string) bool {
+	for _, pool := range f.InstancePools {
+		if pool == instanceType {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fleet) addPool(instanceType ec2types.InstanceType) {
+	if instanceType != "" && !f.hasPool(string(instanceType)) {
+		f.InstancePools = append(f.InstancePools, string(instanceType))
+	}
+}
+
+func newSpotFleet(r ec2types.SpotFleetRequestConfig) *Fleet {
+	config := r.SpotFleetRequestConfig
+	ret := Fleet{
+		ID:                     aws.ToString(r.SpotFleetRequestId),
+		Kind:                   FleetKindSpotFleet,
+		Type:                   string(config.Type),
+		AllocationStrategy:     string(config.AllocationStrategy),
+		TargetCapacity:         aws.ToInt32(config.TargetCapacity),
+		OnDemandTargetCapacity: aws.ToInt32(config.OnDemandTargetCapacity),
+	}
+	for _, spec := range config.LaunchSpecifications {
+		ret.addPool(spec.InstanceType)
+		ret.setWeight(spec.InstanceType, spec.WeightedCapacity)
+	}
+	for _, lt := range config.LaunchTemplateConfigs {
+		if len(lt.Overrides) == 0 {
+			ret.poolsFromInstances = true
+		}
+		for _, o := range lt.Overrides {
+			ret.addPool(o.InstanceType)
+			ret.setWeight(o.InstanceType, o.WeightedCapacity)
+		}
+	}
+	return &ret
+}
+
+func newEC2Fleet(d ec2types.FleetData) *Fleet {
+	ret := Fleet{
+		ID:   aws.ToString(d.FleetId),
+		Kind: FleetKindEC2Fleet,
+		Type: string(d.Type),
+	}
+	if d.SpotOptions != nil {
+		ret.AllocationStrategy = string(d.SpotOptions.AllocationStrategy)
+	}
+	if t := d.TargetCapacitySpecification; t != nil {
+		ret.TargetCapacity = aws.ToInt32(t.TotalTargetCapacity)
+		ret.OnDemandTargetCapacity = aws.ToInt32(t.OnDemandTargetCapacity)
+	}
+	for _, lt := range d.LaunchTemplateConfigs {
+		if len(lt.Overrides) == 0 {
+			ret.poolsFromInstances = true
+		}
+		for _, o := range lt.Overrides {
+			ret.addPool(o.InstanceType)
+			ret.setWeight(o.InstanceType, o.WeightedCapacity)
+		}
+	}
+	return &ret
+}
+
+// isBurstable returns true for the T family instance types, which don't make
+// good alternatives for the fixed performance ones.
+func isBurstable(instanceType string) bool {
+	return len(instanceType) > 1 && instanceType[0] == 't' && instanceType[1] >= '0' && instanceType[1] <= '9'
+}
+
+// poolAlternatives returns the instance types with the same number of vCPUs
+// and GPUs, at least as much memory and a shared CPU architecture, sorted by
+// their Spot price for the given platform.
+func (r *Region) poolAlternatives(instanceType, platform string) []string {
+	var found bool
+	var vcpu, gpu int
+	var memory float32
+	var arch []string
+	for _, i := range *r.instanceTypeData {
+		if i.InstanceType == instanceType {
+			found = true
+			vcpu, gpu, memory, arch = i.VCPU, i.GPU, i.Memory, i.Arch
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	sharesArch := func(other []string) bool {
+		for _, a := range other {
+			for _, b := range arch {
+				if a == b {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	prices := map[string]float64{}
+	var ret []string
+	for _, i := range *r.instanceTypeData {
+		if i.InstanceType == instanceType || i.VCPU != vcpu || i.GPU != gpu ||
+			i.Memory < memory || !sharesArch(i.Arch) || isBurstable(i.InstanceType) != isBurstable(instanceType) {
+			continue
+		}
+		if p := r.productPricing(i.Pricing[r.name], i.InstanceType, r.name, platform); p.SpotMin > 0 {
+			prices[i.InstanceType] = p.SpotMin
+			ret = append(ret, i.InstanceType)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return prices[ret[i]] < prices[ret[j]] })
+	return ret
+}
+
+// addInstance records a running instance of the fleet.
+func (f *Fleet) addInstance(i ec2types.Instance) {
+	if f.poolsFromInstances || len(f.InstancePools) == 0 || i.InstanceLifecycle == ec2types.InstanceLifecycleTypeSpot {
+		f.addPool(i.InstanceType)
+	}
+	f.instances = append(f.instances, i)
+}
+
+// calculateCosts prices the running instances of the fleet with the current
+// discounts, and suggests the instance pools it could add.
+func (f *Fleet) calculateCosts(r *Region, alternatives map[string][]string) {
+	f.OnDemandInstances, f.SpotInstances = 0, 0
+	f.OnDemandCapacity, f.SpotCapacity = 0, 0
+	f.HourlyCosts, f.PoolSavings = 0, 0
+	f.onDemandSavings, f.SuggestedPools = nil, nil
+
+	for _, i := range f.instances {
+		f.priceInstance(r, i, alternatives)
+	}
+	sort.SliceStable(f.onDemandSavings, func(i, j int) bool {
+		return f.onDemandSavings[i].savings > f.onDemandSavings[j].savings
+	})
+	f.suggestPools(alternatives)
+}
+
+func (f *Fleet) priceInstance(r *Region, i ec2types.Instance, alternatives map[string][]string) {
+	instanceType, platform := string(i.InstanceType), aws.ToString(i.PlatformDetails)
+
+	pricing := r.hourlyPricing(instanceType, r.name, platform)
+	weight := f.weight(instanceType)
+	if i.InstanceLifecycle != ec2types.InstanceLifecycleTypeSpot {
+		f.OnDemandInstances++
+		f.OnDemandCapacity += weight
+		f.HourlyCosts += pricing.OnDemand
+		if pricing.SpotMin > 0 {
+			f.onDemandSavings = append(f.onDemandSavings, onDemandSavings{pricing.OnDemand - pricing.SpotMin, weight})
+		}
+		return
+	}
+
+	f.SpotInstances++
+	f.SpotCapacity += weight
+	f.HourlyCosts += pricing.SpotMin
+
+	key := instanceType + "/" + platform
+	if _, ok := alternatives[key]; !ok {
+		alternatives[key] = r.poolAlternatives(instanceType, platform)
+	}
+	for _, alt := range alternatives[key] {
+		if f.hasPool(alt) {
+			continue
+		}
+		if p := r.hourlyPricing(alt, r.name, platform); p.SpotMin < pricing.SpotMin {
+			f.PoolSavings += pricing.SpotMin - p.SpotMin
+		}
+		break
+	}
+}
+
+// suggestPools picks the cheapest alternatives of the Spot instance types
+// which aren't already used by the fleet.
+func (f *Fleet) suggestPools(alternatives map[string][]string) {
+	used := map[string]bool{}
+	var keys []string
+	for key := range alternatives {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, pool := range f.InstancePools {
+		for _, key := range keys {
+			if !strings.HasPrefix(key, pool+"/") {
+				continue
+			}
+			for _, alt := range alternatives[key] {
+				if len(f.SuggestedPools) == maxSuggestedPools {
+					return
+				}
+				if !used[alt] && !f.hasPool(alt) {
+					used[alt] = true
+					f.SuggestedPools = append(f.SuggestedPools, alt)
+				}
+			}
+		}
+	}
+}
+
+// LoadFleets loads the active Spot Fleet requests and EC2 Fleets of the
+// current region, along with their running instances.
+func (c *Launcher) LoadFleets() error {
+	r := c.Regions[c.CurrentRegion]
+	if r == nil {
+		return fmt.Errorf("not connected to the region %s", c.CurrentRegion)
+	}
+	ctx := context.TODO()
+	fleets := map[string]*Fleet{}
+
+	spotFleets := ec2.NewDescribeSpotFleetRequestsPaginator(r.services.ec2, &ec2.DescribeSpotFleetRequestsInput{})
+	for spotFleets.HasMorePages() {
+		out, err := spotFleets.NextPage(ctx)
+		if err != nil {
+			log.Println("Error describing the Spot Fleet requests:", err)
+			return err
+		}
+		for _, config := range out.SpotFleetRequestConfigs {
+			if config.SpotFleetRequestState == ec2types.BatchStateActive || config.SpotFleetRequestState == ec2types.BatchStateModifying {
+				fleets[aws.ToString(config.SpotFleetRequestId)] = newSpotFleet(config)
+			}
+		}
+	}
+
+	ec2Fleets := ec2.NewDescribeFleetsPaginator(r.services.ec2, &ec2.DescribeFleetsInput{})
+	for ec2Fleets.HasMorePages() {
+		out, err := ec2Fleets.NextPage(ctx)
+		if err != nil {
+			log.Println("Error describing the EC2 Fleets:", err)
+			return err
+		}
+		for _, fleet := range out.Fleets {
+			if fleet.FleetState == ec2types.FleetStateCodeActive || fleet.FleetState == ec2types.FleetStateCodeModifying {
+				fleets[aws.ToString(fleet.FleetId)] = newEC2Fleet(fleet)
+			}
+		}
+	}
+
+	instances := ec2.NewDescribeInstancesPaginator(r.services.ec2, &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{
+			{Name: aws.String("instance-state-name"), Values: []string{"running"}},
+			{Name: aws.String("tag-key"), Values: []string{tagSpotFleetRequest, tagEC2Fleet}},
+		},
+	})
+	for instances.HasMorePages() {
+		out, err := instances.NextPage(ctx)
+		if err != nil {
+			log.Println("Error describing the fleet instances:", err)
+			return err
+		}
+		for _, reservation := range out.Reservations {
+			for _, i := range reservation.Instances {
+				id, ok := instanceTag(i.Tags, tagSpotFleetRequest)
+				if !ok {
+					id, _ = instanceTag(i.Tags, tagEC2Fleet)
+				}
+				if fleet, ok := fleets[id]; ok {
+					fleet.addInstance(i)
+				}
+			}
+		}
+	}
+
+	r.Fleets = nil
+	for _, fleet := range fleets {
+		r.Fleets = append(r.Fleets, fleet)
+	}
+	r.calculateFleetCosts()
+	log.Printf("Found %d active fleets in %s", len(r.Fleets), r.name)
+	return nil
+}
+
+// calculateFleetCosts prices the fleets of the region, listing the most
+// expensive ones first.
+func (r *Region) calculateFleetCosts() {
+	alternatives := map[string][]string{}
+	for _, fleet := range r.Fleets {
+		fleet.calculateCosts(r, alternatives)
+	}
+	sort.Slice(r.Fleets, func(i, j int) bool { return r.Fleets[i].HourlyCosts > r.Fleets[j].HourlyCosts })
+}
+
+// Fleets returns the fleets loaded for the current region.
+func (c *Launcher) Fleets() []*Fleet {
+	if c.Regions == nil || c.Regions[c.CurrentRegion] == nil {
+		return nil
+	}
+	return c.Regions[c.CurrentRegion].Fleets
+}
+
+// FleetTotals returns the costs of the fleets over the selected horizon and
+// in the selected currency, with their savings when raising the Spot share
+// to the given percentage and when widening their instance pools.
+func (c *Launcher) FleetTotals(targetSpotPercent float64) FleetTotals {
+	var ret FleetTotals
+	multiplier := c.CostMultiplier()
+	for _, f := range c.Fleets() {
+		ret.Fleets++
+		ret.OnDemandInstances += f.OnDemandInstances
+		ret.SpotInstances += f.SpotInstances
+		ret.CurrentCosts += f.HourlyCosts * multiplier
+		ret.SpotShareSavings += f.SpotShareSavings(targetSpotPercent) * multiplier
+		ret.PoolSavings += f.PoolSavings * multiplier
+	}
+	return ret
+}
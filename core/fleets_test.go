@@ -0,0 +1,41 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFleetSpotShare(t *testing.T) {
+	// A weighted fleet running 4 units on Spot and 4 units on OnDemand, with
+	// the OnDemand capacity split between a large and two small instances.
+	f := Fleet{
+		OnDemandCapacity: 4,
+		SpotCapacity:     4,
+		onDemandSavings: []onDemandSavings{
+			{savings: 0.3, weight: 2},
+			{savings: 0.2, weight: 1},
+			{savings: 0.1, weight: 1},
+		},
+	}
+
+	if got := f.SpotSharePercent(); got != 50 {
+		t.Errorf("SpotSharePercent() = %v, want 50", got)
+	}
+
+	tests := []struct {
+		target float64
+		want   float64
+	}{
+		{target: 0, want: 0},
+		{target: 50, want: 0},
+		{target: 60, want: 0.3},
+		{target: 75, want: 0.3},
+		{target: 80, want: 0.5},
+		{target: 100, want: 0.6},
+	}
+	for _, tt := range tests {
+		if got := f.SpotShareSavings(tt.target); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("SpotShareSavings(%v) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ForecastGrowthHelp describes the syntax of the growth rules, for displaying
+// it in the UI.
+const ForecastGrowthHelp = `One rule per line, with the monthly growth percentage after a comma:
+
+  asg:web-prod,5      the AutoScaling group named "web-prod" grows 5% a month
+  tag:team=data,-2    the ones tagged with team=data shrink 2% a month
+
+AutoScaling group rules take precedence over tag rules, and the first
+matching tag rule applies. The others grow at the default rate.`
+
+// ForecastRolloutHelp describes the syntax of the rollout schedule.
+const ForecastRolloutHelp = `Comma separated stages of month:percentage, such as 1:25, 2:50, 4:100,
+giving the share of the Spot conversion rolled out from each month on.
+Empty means the whole conversion from the first month.`
+
+// GrowthRule is the monthly growth rate of the fleet of an ASG, or of the
+// ASGs with a given tag.
+type GrowthRule struct {
+	ASG            string
+	TagKey         string
+	TagValue       string
+	MonthlyPercent float64
+}
+
+func (r GrowthRule) matches(asg *ASG) bool {
+	if r.ASG != "" {
+		return *asg.AutoScalingGroupName == r.ASG
+	}
+	v, ok := asg.tagValue(r.TagKey)
+	return ok && v == r.TagValue
+}
+
+// RolloutStage is the share of the Spot conversion rolled out from the given
+// month on.
+type RolloutStage struct {
+	Month   int
+	Percent float64
+}
+
+// ForecastSettings holds the assumptions of the forecast.
+type ForecastSettings struct {
+	Months        int
+	DefaultGrowth float64
+	Growth        []GrowthRule
+	Rollout       []RolloutStage
+	// MigrationCost is the one-off effort of the conversion, in the selected
+	// currency.
+	MigrationCost float64
+}
+
+// ForecastMonth holds the monthly figures of the forecast, in the selected
+// currency.
+type ForecastMonth struct {
+	Month                int
+	RolloutPercent       float64
+	CurrentCosts         float64
+	ProjectedCosts       float64
+	SpotSavings          float64
+	Fee                  float64
+	NetSavings           float64
+	CumulativeNetSavings float64
+	// AfterMigration is the cumulative net savings minus the migration cost.
+	AfterMigration float64
+}
+
+// Forecast projects the monthly costs of the ASGs over the next months.
+type Forecast struct {
+	Months   []ForecastMonth
+	Currency string
+	// BreakEvenMonth is the first month when the cumulative net savings cover
+	// the migration cost, or 0 if that doesn't happen within the forecast.
+	BreakEvenMonth int
+}
+
+// ParseGrowthRules parses the growth rules, in the format described by
+// ForecastGrowthHelp.
+func ParseGrowthRules(text string) ([]GrowthRule, error) {
+	var ret []GrowthRule
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		target, percent, ok := strings.Cut(line, ",")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing the growth percentage in %q", i+1, line)
+		}
+
+		var r GrowthRule
+		var err error
+		r.MonthlyPercent, err = strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid growth percentage %q", i+1, percent)
+		}
+		if r.MonthlyPercent <= -100 {
+			return nil, fmt.Errorf("line %d: the fleet can't shrink by 100%% or more a month", i+1)
+		}
+
+		kind, match, _ := strings.Cut(strings.TrimSpace(target), ":")
+		switch kind {
+		case "asg":
+			r.ASG = match
+		case "tag":
+			r.TagKey, r.TagValue, ok = strings.Cut(match, "=")
+			if !ok || r.TagKey == "" {
+				return nil, fmt.Errorf("line %d: expected tag:key=value, got %q", i+1, target)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: expected asg:name or tag:key=value, got %q", i+1, target)
+		}
+		if match == "" {
+			return nil, fmt.Errorf("line %d: missing the %s to match", i+1, kind)
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+// ParseRolloutSchedule parses the rollout stages, in the format described by
+// ForecastRolloutHelp, sorted by month.
+func ParseRolloutSchedule(text string) ([]RolloutStage, error) {
+	var ret []RolloutStage
+	for _, token := range strings.Split(text, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		month, percent, ok := strings.Cut(token, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected month:percentage, got %q", token)
+		}
+
+		var s RolloutStage
+		var err error
+		if s.Month, err = strconv.Atoi(strings.TrimSpace(month)); err != nil || s.Month < 1 {
+			return nil, fmt.Errorf("invalid month in %q", token)
+		}
+		if s.Percent, err = strconv.ParseFloat(strings.TrimSpace(percent), 64); err != nil || s.Percent < 0 || s.Percent > 100 {
+			return nil, fmt.Errorf("invalid percentage in %q, must be between 0 and 100", token)
+		}
+		ret = append(ret, s)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].Month < ret[j].Month })
+	return ret, nil
+}
+
+func (s ForecastSettings) growth(asg *ASG) float64 {
+	for _, r := range s.Growth {
+		if r.ASG != "" && r.matches(asg) {
+			return r.MonthlyPercent
+		}
+	}
+	for _, r := range s.Growth {
+		if r.ASG == "" && r.matches(asg) {
+			return r.MonthlyPercent
+		}
+	}
+	return s.DefaultGrowth
+}
+
+// rollout returns the share of the Spot conversion rolled out in the month.
+func (s ForecastSettings) rollout(month int) float64 {
+	if len(s.Rollout) == 0 {
+		return 100
+	}
+	ret := 0.0
+	for _, stage := range s.Rollout {
+		if stage.Month > month {
+			break
+		}
+		ret = stage.Percent
+	}
+	return ret
+}
+
+// Forecast projects the monthly costs of the ASGs matching the current
+// filter, like the totals. The first month uses the current fleet size, and
+// the fee is calculated from the savings of each month.
+func (c *Launcher) Forecast(s ForecastSettings) (*Forecast, error) {
+	if s.Months < 1 {
+		return nil, errors.New("the forecast needs at least one month")
+	}
+	if s.DefaultGrowth <= -100 {
+		return nil, errors.New("the fleet can't shrink by 100% or more a month")
+	}
+
+	asgs := c.VisibleASGs()
+	if len(asgs) == 0 {
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+
+	fee, rate := c.feeModel(), c.ExchangeRate()
+	f := Forecast{Currency: c.CurrencyCode()}
+
+	var cumulative float64
+	for m := 1; m <= s.Months; m++ {
+		month := ForecastMonth{Month: m, RolloutPercent: s.rollout(m)}
+
+		for _, asg := range asgs {
+			size := math.Pow(1+s.growth(asg)/100, float64(m-1))
+			month.CurrentCosts += asg.HourlyCosts * hoursPerMonth * size
+			if asg.Enabled {
+				month.SpotSavings += asg.ProjectedSavings * hoursPerMonth * size * month.RolloutPercent / 100
+			}
+		}
+		month.ProjectedCosts = month.CurrentCosts - month.SpotSavings
+		month.Fee = fee.MonthlyFee(month.SpotSavings)
+
+		month.CurrentCosts *= rate
+		month.ProjectedCosts *= rate
+		month.SpotSavings *= rate
+		month.Fee *= rate
+		month.NetSavings = month.SpotSavings - month.Fee
+
+		cumulative += month.NetSavings
+		month.CumulativeNetSavings = cumulative
+		month.AfterMigration = cumulative - s.MigrationCost
+		if f.BreakEvenMonth == 0 && cumulative > 0 && cumulative >= s.MigrationCost {
+			f.BreakEvenMonth = m
+		}
+
+		f.Months = append(f.Months, month)
+	}
+	return &f, nil
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGrowthRules(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []GrowthRule
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"\n  \n", nil, false},
+		{"asg:web-prod,5", []GrowthRule{{ASG: "web-prod", MonthlyPercent: 5}}, false},
+		{" tag:team=data , -2.5 \nasg:batch,0", []GrowthRule{
+			{TagKey: "team", TagValue: "data", MonthlyPercent: -2.5},
+			{ASG: "batch", MonthlyPercent: 0},
+		}, false},
+		{"tag:team=,1", []GrowthRule{{TagKey: "team", MonthlyPercent: 1}}, false},
+		{"asg:web", nil, true},
+		{"asg:web,x", nil, true},
+		{"asg:web,-100", nil, true},
+		{"asg:,5", nil, true},
+		{"tag:team,5", nil, true},
+		{"tag:=data,5", nil, true},
+		{"web,5", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseGrowthRules(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseGrowthRules(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseGrowthRules(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseRolloutSchedule(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []RolloutStage
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"1:25, 2:50, 4:100", []RolloutStage{{1, 25}, {2, 50}, {4, 100}}, false},
+		{"4:100,1:25,", []RolloutStage{{1, 25}, {4, 100}}, false},
+		{"2:12.5", []RolloutStage{{2, 12.5}}, false},
+		{"1", nil, true},
+		{"0:50", nil, true},
+		{"x:50", nil, true},
+		{"1:101", nil, true},
+		{"1:-1", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRolloutSchedule(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRolloutSchedule(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRolloutSchedule(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+)
+
+const (
+	IntervalHourly  = "hourly"
+	IntervalDaily   = "daily"
+	IntervalMonthly = "monthly"
+	IntervalYearly  = "yearly"
+	IntervalCustom  = "custom"
+
+	hoursPerMonth = 730
+)
+
+var PricingIntervals = []string{IntervalHourly, IntervalDaily, IntervalMonthly, IntervalYearly, IntervalCustom}
+
+// Horizon is the time interval used for all the costs and savings displayed
+// in the table, the totals and the exports. Days is only used by the custom
+// interval.
+type Horizon struct {
+	Interval string  `json:"interval"`
+	Days     float64 `json:"days,omitempty"`
+}
+
+// Hours returns the number of hours in the horizon.
+func (h Horizon) Hours() float64 {
+	switch h.Interval {
+	case IntervalDaily:
+		return 24
+	case IntervalMonthly:
+		return hoursPerMonth
+	case IntervalYearly:
+		return 8760
+	case IntervalCustom:
+		return h.Days * 24
+	}
+	return 1
+}
+
+// Label describes the horizon in texts such as "monthly costs".
+func (h Horizon) Label() string {
+	switch h.Interval {
+	case "":
+		return IntervalHourly
+	case IntervalCustom:
+		return fmt.Sprintf("%g-day", h.Days)
+	}
+	return h.Interval
+}
+
+func (h Horizon) validate() error {
+	for _, i := range PricingIntervals {
+		if h.Interval != i {
+			continue
+		}
+		if h.Interval == IntervalCustom && h.Days <= 0 {
+			return fmt.Errorf("the custom interval needs a positive number of days")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown pricing interval %q", h.Interval)
+}
+
+// SetHorizon sets the interval used for the costs and savings.
+func (c *Launcher) SetHorizon(h Horizon) error {
+	if err := h.validate(); err != nil {
+		return err
+	}
+	c.Horizon = h
+	c.PricingIntervalMultiplier = h.Hours()
+	return nil
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	IAMRoleName       = "SavingsEstimatorIAMRole"
+	iamPolicyName     = "SavingsEstimatorIAMRolePolicy"
+	iamPolicyVersion  = "2012-10-17"
+	cfnTemplateFormat = "2010-09-09"
+
+	// partitionVariable is substituted by CloudFormation in the ARNs of the
+	// generated template, and matches any partition in the plain policy.
+	partitionVariable = "${AWS::Partition}"
+)
+
+type policyStatement struct {
+	Effect   string      `json:"Effect" yaml:"Effect"`
+	Action   []string    `json:"Action" yaml:"Action"`
+	Resource interface{} `json:"Resource" yaml:"Resource"`
+}
+
+type policyDocument struct {
+	Version   string            `json:"Version" yaml:"Version"`
+	Statement []policyStatement `json:"Statement" yaml:"Statement"`
+}
+
+// policyForFeatures builds a least-privilege policy out of the permissions
+// registry, with one statement for each distinct resource.
+func policyForFeatures(features []Feature) policyDocument {
+	byResource := map[string][]string{}
+	for _, p := range permissions {
+		if !p.hasFeature(features) {
+			continue
+		}
+		byResource[p.resource()] = append(byResource[p.resource()], p.Action)
+	}
+
+	resources := []string{}
+	for r := range byResource {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+
+	doc := policyDocument{Version: iamPolicyVersion}
+	for _, r := range resources {
+		actions := byResource[r]
+		sort.Strings(actions)
+		doc.Statement = append(doc.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   actions,
+			Resource: r,
+		})
+	}
+	return doc
+}
+
+// IAMPolicyDocument returns the JSON IAM policy needed by the given features.
+func IAMPolicyDocument(features []Feature) ([]byte, error) {
+	doc := policyForFeatures(features)
+	for i, s := range doc.Statement {
+		doc.Statement[i].Resource = strings.ReplaceAll(s.Resource.(string), partitionVariable, "*")
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// CloudFormationTemplate returns a CloudFormation template creating an IAM
+// role with the policy needed by the given features. The role can be assumed
+// from the same AWS account, as documented in the README.
+func CloudFormationTemplate(features []Feature) ([]byte, error) {
+	doc := policyForFeatures(features)
+	for i, s := range doc.Statement {
+		if r := s.Resource.(string); strings.Contains(r, partitionVariable) {
+			doc.Statement[i].Resource = yaml.MapSlice{{Key: "Fn::Sub", Value: r}}
+		}
+	}
+
+	template := yaml.MapSlice{
+		{Key: "AWSTemplateFormatVersion", Value: cfnTemplateFormat},
+		{Key: "Description", Value: "IAM role used by the LeanerCloud Savings Estimator"},
+		{Key: "Resources", Value: yaml.MapSlice{
+			{Key: IAMRoleName, Value: yaml.MapSlice{
+				{Key: "Type", Value: "AWS::IAM::Role"},
+				{Key: "Properties", Value: yaml.MapSlice{
+					{Key: "RoleName", Value: IAMRoleName},
+					{Key: "AssumeRolePolicyDocument", Value: yaml.MapSlice{
+						{Key: "Version", Value: iamPolicyVersion},
+						{Key: "Statement", Value: []yaml.MapSlice{{
+							{Key: "Effect", Value: "Allow"},
+							{Key: "Principal", Value: yaml.MapSlice{
+								{Key: "AWS", Value: yaml.MapSlice{
+									{Key: "Fn::Sub", Value: "arn:${AWS::Partition}:iam::${AWS::AccountId}:root"},
+								}},
+							}},
+							{Key: "Action", Value: []string{"sts:AssumeRole"}},
+						}}},
+					}},
+					{Key: "Path", Value: "/"},
+					{Key: "Policies", Value: []yaml.MapSlice{{
+						{Key: "PolicyName", Value: iamPolicyName},
+						{Key: "PolicyDocument", Value: doc},
+					}}},
+				}},
+			}},
+		}},
+		{Key: "Outputs", Value: yaml.MapSlice{
+			{Key: IAMRoleName + "Arn", Value: yaml.MapSlice{
+				{Key: "Description", Value: "The ARN of the " + IAMRoleName + " IAM role"},
+				{Key: "Value", Value: yaml.MapSlice{
+					{Key: "Fn::GetAtt", Value: []string{IAMRoleName, "Arn"}},
+				}},
+			}},
+		}},
+	}
+	return yaml.Marshal(template)
+}
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"os"
+	"testing"
+)
+
+// TestCloudFormationTemplateUpToDate fails when the permission registry
+// changes without regenerating the template with go generate.
+func TestCloudFormationTemplateUpToDate(t *testing.T) {
+	want, err := CloudFormationTemplate(TemplateFeatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile("../cloudformation/template.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Error("cloudformation/template.yaml is out of date, run go generate")
+	}
+}
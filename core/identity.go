@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Identity describes the AWS account and principal behind the credentials
+// we're connected with.
+type Identity struct {
+	AccountID string
+	Alias     string
+	ARN       string
+}
+
+func (i *Identity) String() string {
+	if i == nil {
+		return "Not connected"
+	}
+
+	account := i.AccountID
+	if i.Alias != "" {
+		account = fmt.Sprintf("%s (%s)", i.AccountID, i.Alias)
+	}
+	return fmt.Sprintf("Account: %s - %s", account, i.ARN)
+}
+
+// validateCredentials makes sure the credentials are usable by calling STS
+// GetCallerIdentity, which doesn't need any IAM permissions. The account
+// alias is only informative, so failing to read it isn't an error.
+func validateCredentials(cfg aws.Config) (*Identity, error) {
+	resp, err := sts.NewFromConfig(cfg).GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("the AWS credentials are invalid or expired: %w", err)
+	}
+
+	identity := Identity{
+		AccountID: aws.ToString(resp.Account),
+		ARN:       aws.ToString(resp.Arn),
+	}
+
+	aliases, err := iam.NewFromConfig(cfg).ListAccountAliases(context.TODO(), &iam.ListAccountAliasesInput{})
+	if err != nil {
+		log.Printf("Couldn't read the IAM account alias: %v", err)
+	} else if len(aliases.AccountAliases) > 0 {
+		identity.Alias = aliases.AccountAliases[0]
+	}
+
+	log.Println("Connected as", identity.String())
+	return &identity, nil
+}
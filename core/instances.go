@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Tags set by AWS on the instances launched by AutoScaling and by fleets.
+const (
+	tagASGName          = "aws:autoscaling:groupName"
+	tagSpotFleetRequest = "aws:ec2spot:fleet-request-id"
+	tagEC2Fleet         = "aws:ec2:fleet-id"
+)
+
+// Tags set on the instances launched by Karpenter and EMR, which manage their
+// own capacity. The EKS nodes are detected from tagEKSClusterName.
+const (
+	tagKarpenterNodePool = "karpenter.sh/nodepool"
+	tagPrefixEMR         = "aws:elasticmapreduce:"
+)
+
+// statefulNameHints are the usual names of stateful workloads, which are
+// unlikely to tolerate Spot interruptions.
+var statefulNameHints = []string{
+	"cassandra", "database", "db", "elastic", "etcd", "jenkins", "kafka",
+	"mongo", "mysql", "nfs", "oracle", "postgres", "rabbit", "redis", "sql",
+	"vault", "zookeeper",
+}
+
+// StandaloneInstance is a running OnDemand instance that doesn't belong to
+// an AutoScaling group or fleet.
+type StandaloneInstance struct {
+	InstanceID   string
+	Name         string
+	InstanceType string
+	Platform     string
+	LaunchTime   time.Time
+	HourlyCosts  float64
+	SpotCosts    float64
+	// SpotSavings are the savings of running the instance on Spot, which
+	// requires moving it into an AutoScaling group first.
+	SpotSavings float64
+	// Stateless is set for the instances without any of the signs of a
+	// stateful workload listed in Reasons.
+	Stateless bool
+	Reasons   []string
+}
+
+// StandaloneTotals aggregates the costs of the standalone instances.
+type StandaloneTotals struct {
+	Instances          int
+	CurrentCosts       float64
+	SpotCosts          float64
+	SpotSavings        float64
+	StatelessInstances int
+	StatelessSavings   float64
+}
+
+func instanceTag(tags []ec2types.Tag, key string) (string, bool) {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == key {
+			return aws.ToString(tag.Value), true
+		}
+	}
+	return "", false
+}
+
+// isStandalone returns true for the OnDemand instances not launched by an
+// AutoScaling group, a fleet, Karpenter, EKS or EMR.
+func isStandalone(i ec2types.Instance) bool {
+	if i.InstanceLifecycle != "" {
+		return false
+	}
+	for _, key := range []string{tagASGName, tagSpotFleetRequest, tagEC2Fleet, tagKarpenterNodePool, tagEKSClusterName} {
+		if _, ok := instanceTag(i.Tags, key); ok {
+			return false
+		}
+	}
+	for _, tag := range i.Tags {
+		if strings.HasPrefix(aws.ToString(tag.Key), tagPrefixEMR) {
+			return false
+		}
+	}
+	return true
+}
+
+// statefulReasons lists the signs of a stateful workload, which make the
+// instance a poor candidate for an AutoScaling group running on Spot.
+func statefulReasons(i ec2types.Instance, name string) []string {
+	var ret []string
+
+	var volumes int
+	for _, bdm := range i.BlockDeviceMappings {
+		if aws.ToString(bdm.DeviceName) != aws.ToString(i.RootDeviceName) {
+			volumes++
+		}
+	}
+	if volumes > 0 {
+		ret = append(ret, fmt.Sprintf("%d data volume(s) attached", volumes))
+	}
+
+	if len(i.NetworkInterfaces) > 1 {
+		ret = append(ret, fmt.Sprintf("%d network interfaces attached", len(i.NetworkInterfaces)))
+	}
+
+	if i.HibernationOptions != nil && aws.ToBool(i.HibernationOptions.Configured) {
+		ret = append(ret, "hibernation enabled")
+	}
+
+	if i.RootDeviceType == ec2types.DeviceTypeInstanceStore {
+		ret = append(ret, "instance store root volume")
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r < 'a' || r > 'z'
+	})
+	for _, word := range words {
+		for _, hint := range statefulNameHints {
+			if word == hint {
+				ret = append(ret, "name suggests "+hint)
+			}
+		}
+	}
+	return ret
+}
+
+func (r *Region) newStandaloneInstance(i ec2types.Instance) *StandaloneInstance {
+	name, _ := instanceTag(i.Tags, "Name")
+	ret := StandaloneInstance{
+		InstanceID:   aws.ToString(i.InstanceId),
+		Name:         name,
+		InstanceType: string(i.InstanceType),
+		Platform:     aws.ToString(i.PlatformDetails),
+		LaunchTime:   aws.ToTime(i.LaunchTime),
+		Reasons:      statefulReasons(i, name),
+	}
+	ret.Stateless = len(ret.Reasons) == 0
+	ret.calculateCosts(r)
+	return &ret
+}
+
+// calculateCosts prices the instance with the current discounts.
+func (s *StandaloneInstance) calculateCosts(r *Region) {
+	s.HourlyCosts, s.SpotCosts, s.SpotSavings = 0, 0, 0
+
+	pricing := r.hourlyPricing(s.InstanceType, r.name, s.Platform)
+	if pricing.OnDemand == 0 {
+		log.Printf("Couldn't determine the pricing of the %s instance %s running %s", s.InstanceType, s.InstanceID, s.Platform)
+		return
+	}
+	s.HourlyCosts = pricing.OnDemand
+	s.SpotCosts = pricing.SpotMin
+	s.SpotSavings = pricing.OnDemand - pricing.SpotMin
+}
+
+// LoadStandaloneInstances loads the running OnDemand instances of the current
+// region that don't belong to an AutoScaling group or fleet.
+func (c *Launcher) LoadStandaloneInstances() error {
+	r := c.Regions[c.CurrentRegion]
+	if r == nil {
+		return fmt.Errorf("not connected to the region %s", c.CurrentRegion)
+	}
+
+	var instances []*StandaloneInstance
+	paginator := ec2.NewDescribeInstancesPaginator(r.services.ec2, &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{{
+			Name:   aws.String("instance-state-name"),
+			Values: []string{"running"},
+		}},
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.Println("Error describing the instances:", err)
+			return err
+		}
+		for _, reservation := range out.Reservations {
+			for _, i := range reservation.Instances {
+				if isStandalone(i) {
+					instances = append(instances, r.newStandaloneInstance(i))
+				}
+			}
+		}
+	}
+
+	r.StandaloneInstances = instances
+	r.sortStandaloneInstances()
+	log.Printf("Found %d standalone OnDemand instances in %s", len(instances), r.name)
+	return nil
+}
+
+// sortStandaloneInstances lists the instances with the largest Spot savings
+// first.
+func (r *Region) sortStandaloneInstances() {
+	instances := r.StandaloneInstances
+	sort.SliceStable(instances, func(i, j int) bool { return instances[i].SpotSavings > instances[j].SpotSavings })
+}
+
+// StandaloneInstances returns the standalone instances loaded for the current
+// region.
+func (c *Launcher) StandaloneInstances() []*StandaloneInstance {
+	if c.Regions == nil || c.Regions[c.CurrentRegion] == nil {
+		return nil
+	}
+	return c.Regions[c.CurrentRegion].StandaloneInstances
+}
+
+// StandaloneTotals returns the costs of the standalone instances over the
+// selected horizon and in the selected currency. The savings are before any
+// AutoSpotting fee, since the instances need to be moved into AutoScaling
+// groups first.
+func (c *Launcher) StandaloneTotals() StandaloneTotals {
+	var ret StandaloneTotals
+	multiplier := c.CostMultiplier()
+	for _, i := range c.StandaloneInstances() {
+		ret.Instances++
+		ret.CurrentCosts += i.HourlyCosts * multiplier
+		ret.SpotCosts += i.SpotCosts * multiplier
+		ret.SpotSavings += i.SpotSavings * multiplier
+		if i.Stateless {
+			ret.StatelessInstances++
+			ret.StatelessSavings += i.SpotSavings * multiplier
+		}
+	}
+	return ret
+}
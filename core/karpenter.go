@@ -0,0 +1,294 @@
+package core
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"gopkg.in/yaml.v2"
+)
+
+var unsafeKubernetesNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// maxKubernetesNameLength keeps the generated names valid as label values.
+const maxKubernetesNameLength = 63
+
+// karpenterName returns a valid Kubernetes name for the resources of the node
+// group. Names that had to be shortened or had no valid characters get a hash
+// of the node group name, so they stay unique.
+func karpenterName(nodeGroup string) string {
+	name := strings.Trim(unsafeKubernetesNameChars.ReplaceAllString(strings.ToLower(nodeGroup), "-"), "-")
+	if name != "" && len(name) <= maxKubernetesNameLength {
+		return name
+	}
+
+	hash := sha256.Sum256([]byte(nodeGroup))
+	suffix := fmt.Sprintf("%x", hash[:4])
+	if name == "" {
+		return "nodegroup-" + suffix
+	}
+	return strings.TrimRight(name[:maxKubernetesNameLength-len(suffix)-1], "-") + "-" + suffix
+}
+
+const karpenterCustomAMIFamily = "Custom"
+
+// karpenterCustomUserData is set for the AMIs that aren't EKS optimized, which
+// Karpenter doesn't know how to bootstrap, so it has to be replaced before
+// applying the configuration.
+const karpenterCustomUserData = `#!/bin/bash
+# PLACEHOLDER: this AMI isn't EKS optimized, so Karpenter doesn't bootstrap it
+# and the nodes won't join the cluster with this script. Replace it with the
+# bootstrap script used by the launch template of the node group.
+`
+
+type karpenterObjectMeta struct {
+	Name string `yaml:"name"`
+}
+
+type karpenterRequirement struct {
+	Key      string   `yaml:"key"`
+	Operator string   `yaml:"operator"`
+	Values   []string `yaml:"values"`
+}
+
+type karpenterNodeClassRef struct {
+	Group string `yaml:"group"`
+	Kind  string `yaml:"kind"`
+	Name  string `yaml:"name"`
+}
+
+type karpenterNodePool struct {
+	APIVersion string              `yaml:"apiVersion"`
+	Kind       string              `yaml:"kind"`
+	Metadata   karpenterObjectMeta `yaml:"metadata"`
+	Spec       struct {
+		Template struct {
+			Spec struct {
+				Requirements []karpenterRequirement `yaml:"requirements"`
+				NodeClassRef karpenterNodeClassRef  `yaml:"nodeClassRef"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+		Disruption struct {
+			ConsolidationPolicy string `yaml:"consolidationPolicy"`
+			ConsolidateAfter    string `yaml:"consolidateAfter"`
+		} `yaml:"disruption"`
+	} `yaml:"spec"`
+}
+
+type karpenterSelectorTerm struct {
+	ID    string            `yaml:"id,omitempty"`
+	Alias string            `yaml:"alias,omitempty"`
+	Tags  map[string]string `yaml:"tags,omitempty"`
+}
+
+type karpenterEC2NodeClass struct {
+	APIVersion string              `yaml:"apiVersion"`
+	Kind       string              `yaml:"kind"`
+	Metadata   karpenterObjectMeta `yaml:"metadata"`
+	Spec       struct {
+		AMIFamily                  string                  `yaml:"amiFamily,omitempty"`
+		AMISelectorTerms           []karpenterSelectorTerm `yaml:"amiSelectorTerms"`
+		Role                       string                  `yaml:"role"`
+		SubnetSelectorTerms        []karpenterSelectorTerm `yaml:"subnetSelectorTerms"`
+		SecurityGroupSelectorTerms []karpenterSelectorTerm `yaml:"securityGroupSelectorTerms"`
+		UserData                   string                  `yaml:"userData,omitempty"`
+	} `yaml:"spec"`
+}
+
+// KarpenterNodeGroup compares the savings of a managed node group when
+// converted with AutoSpotting and when replaced by the generated Karpenter
+// NodePool, over the selected horizon and in the selected currency.
+type KarpenterNodeGroup struct {
+	NodeGroup    string
+	ASG          string
+	CurrentCosts float64
+	// AutoSpottingNetSavings uses the OnDemand overrides of the ASG and the
+	// selected fee model.
+	AutoSpottingNetSavings float64
+	// KarpenterSavings assumes the same instance types, all running on Spot
+	// since Karpenter prefers Spot capacity when both capacity types are
+	// allowed.
+	KarpenterSavings float64
+}
+
+// KarpenterConfig holds the NodePool and EC2NodeClass resources generated for
+// the managed node groups of an EKS cluster. Warnings lists what needs to be
+// completed by hand before applying them.
+type KarpenterConfig struct {
+	Cluster    string
+	NodeGroups []KarpenterNodeGroup
+	YAML       string
+	Warnings   []string
+}
+
+func (asg *ASG) readSecurityGroups(data *ec2types.ResponseLaunchTemplateData) {
+	asg.securityGroups = append([]string{}, data.SecurityGroupIds...)
+	for _, ni := range data.NetworkInterfaces {
+		asg.securityGroups = append(asg.securityGroups, ni.Groups...)
+	}
+}
+
+// karpenterAMI returns the AMI family and selector of the node group AMI. The
+// EKS optimized AMIs are selected by alias, so Karpenter keeps them up to
+// date, other AMIs are selected by ID and need their own user data.
+func (asg *ASG) karpenterAMI() (string, karpenterSelectorTerm) {
+	name := strings.ToLower(asg.amiName)
+	switch {
+	case strings.Contains(name, "bottlerocket"):
+		return "", karpenterSelectorTerm{Alias: "bottlerocket@latest"}
+	case strings.Contains(name, "al2023"):
+		return "", karpenterSelectorTerm{Alias: "al2023@latest"}
+	case strings.Contains(name, "windows") && strings.Contains(name, "2019"):
+		return "", karpenterSelectorTerm{Alias: "windows2019@latest"}
+	case strings.Contains(name, "windows"):
+		return "", karpenterSelectorTerm{Alias: "windows2022@latest"}
+	case strings.HasPrefix(name, "amazon-eks"):
+		return "", karpenterSelectorTerm{Alias: "al2@latest"}
+	}
+	return karpenterCustomAMIFamily, karpenterSelectorTerm{ID: asg.ami}
+}
+
+func (asg *ASG) karpenterResources() []interface{} {
+	name := karpenterName(asg.EKSNodeGroup)
+
+	var nodeClass karpenterEC2NodeClass
+	nodeClass.APIVersion = "karpenter.k8s.aws/v1"
+	nodeClass.Kind = "EC2NodeClass"
+	nodeClass.Metadata.Name = name
+
+	family, ami := asg.karpenterAMI()
+	nodeClass.Spec.AMIFamily = family
+	nodeClass.Spec.AMISelectorTerms = []karpenterSelectorTerm{ami}
+	if family == karpenterCustomAMIFamily {
+		nodeClass.Spec.UserData = karpenterCustomUserData
+	}
+	// The instance profile of managed node groups is set by EKS, so the role
+	// follows the naming convention from the Karpenter documentation.
+	nodeClass.Spec.Role = "KarpenterNodeRole-" + asg.EKSCluster
+
+	for _, subnet := range strings.Split(aws.ToString(asg.VPCZoneIdentifier), ",") {
+		if subnet = strings.TrimSpace(subnet); subnet != "" {
+			nodeClass.Spec.SubnetSelectorTerms = append(nodeClass.Spec.SubnetSelectorTerms, karpenterSelectorTerm{ID: subnet})
+		}
+	}
+	for _, sg := range asg.securityGroups {
+		nodeClass.Spec.SecurityGroupSelectorTerms = append(nodeClass.Spec.SecurityGroupSelectorTerms, karpenterSelectorTerm{ID: sg})
+	}
+	// Without a custom launch template, the nodes only use the cluster
+	// security group.
+	if len(asg.securityGroups) == 0 {
+		nodeClass.Spec.SecurityGroupSelectorTerms = []karpenterSelectorTerm{{
+			Tags: map[string]string{"aws:eks:cluster-name": asg.EKSCluster},
+		}}
+	}
+
+	var nodePool karpenterNodePool
+	nodePool.APIVersion = "karpenter.sh/v1"
+	nodePool.Kind = "NodePool"
+	nodePool.Metadata.Name = name
+	nodePool.Spec.Template.Spec.Requirements = []karpenterRequirement{
+		{Key: "karpenter.sh/capacity-type", Operator: "In", Values: []string{"spot", "on-demand"}},
+		{Key: "node.kubernetes.io/instance-type", Operator: "In", Values: asg.InstanceTypes},
+	}
+	nodePool.Spec.Template.Spec.NodeClassRef = karpenterNodeClassRef{
+		Group: "karpenter.k8s.aws",
+		Kind:  "EC2NodeClass",
+		Name:  name,
+	}
+	nodePool.Spec.Disruption.ConsolidationPolicy = "WhenEmptyOrUnderutilized"
+	nodePool.Spec.Disruption.ConsolidateAfter = "1m"
+
+	return []interface{}{nodePool, nodeClass}
+}
+
+// karpenterHourlySavings returns the hourly savings of running all the
+// instances of the ASG on Spot.
+func (asg *ASG) karpenterHourlySavings() float64 {
+	if asg.spotProduct == nil {
+		return 0
+	}
+	var ret float64
+	for _, instance := range asg.Instances {
+		pricing := asg.getHourlyPricing("cost", *instance.InstanceType, asg.region.name, *asg.spotProduct)
+		if pricing == nil {
+			continue
+		}
+		ret += pricing.OnDemand - pricing.SpotMin
+	}
+	return ret
+}
+
+// KarpenterConfig generates a Karpenter NodePool and EC2NodeClass for each
+// managed node group of the EKS cluster from the current region matching the
+// filter, like the cluster totals, based on their launch templates.
+func (c *Launcher) KarpenterConfig(cluster string) (*KarpenterConfig, error) {
+	var asgs []*ASG
+	for _, asg := range c.VisibleASGs() {
+		if asg.IsManagedNodeGroup() && asg.EKSCluster == cluster {
+			asgs = append(asgs, asg)
+		}
+	}
+	if len(asgs) == 0 {
+		return nil, fmt.Errorf("no managed node groups found for the EKS cluster %q", cluster)
+	}
+	sort.Slice(asgs, func(i, j int) bool { return asgs[i].EKSNodeGroup < asgs[j].EKSNodeGroup })
+
+	ret := KarpenterConfig{Cluster: cluster}
+	multiplier := c.CostMultiplier()
+
+	// The fee is calculated on the savings of the whole cluster, and each node
+	// group gets a share proportional to its savings.
+	var enabled []*ASG
+	for _, asg := range asgs {
+		e := *asg
+		e.Enabled = true
+		enabled = append(enabled, &e)
+	}
+	clusterTotals := totals(enabled, c.feeModel(), c.PricingIntervalMultiplier).converted(c.ExchangeRate())
+
+	var docs []string
+	for i, asg := range asgs {
+		if len(asg.InstanceTypes) == 0 {
+			return nil, fmt.Errorf("couldn't determine the instance types of the node group %s", asg.EKSNodeGroup)
+		}
+
+		for _, r := range asg.karpenterResources() {
+			data, err := yaml.Marshal(r)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, string(data))
+		}
+
+		if family, _ := asg.karpenterAMI(); family == karpenterCustomAMIFamily {
+			ret.Warnings = append(ret.Warnings, fmt.Sprintf("The node group %s uses the custom AMI %s, replace the "+
+				"placeholder userData of its EC2NodeClass with the bootstrap script of the AMI, or its nodes won't "+
+				"join the cluster.", asg.EKSNodeGroup, asg.ami))
+		}
+
+		savings := enabled[i].ProjectedSavings * multiplier
+		var fee float64
+		if clusterTotals.SpotSavings > 0 {
+			fee = clusterTotals.Fee * savings / clusterTotals.SpotSavings
+		}
+
+		ret.NodeGroups = append(ret.NodeGroups, KarpenterNodeGroup{
+			NodeGroup:              asg.EKSNodeGroup,
+			ASG:                    *asg.AutoScalingGroupName,
+			CurrentCosts:           asg.HourlyCosts * multiplier,
+			AutoSpottingNetSavings: savings - fee,
+			KarpenterSavings:       asg.karpenterHourlySavings() * multiplier,
+		})
+	}
+
+	if len(docs) == 0 {
+		return nil, errors.New("no Karpenter resources generated")
+	}
+	ret.YAML = strings.Join(docs, "---\n")
+	return &ret, nil
+}
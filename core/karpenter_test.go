@@ -0,0 +1,37 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKarpenterName(t *testing.T) {
+	long := strings.Repeat("a", 70)
+	tests := []struct {
+		nodeGroup string
+		want      string
+	}{
+		{"workers", "workers"},
+		{"Workers_Spot", "workers-spot"},
+		{"_workers_", "workers"},
+		{"--a.b--", "a-b"},
+		{strings.Repeat("a", 63), strings.Repeat("a", 63)},
+	}
+
+	for _, tt := range tests {
+		if got := karpenterName(tt.nodeGroup); got != tt.want {
+			t.Errorf("karpenterName(%q) = %q, want %q", tt.nodeGroup, got, tt.want)
+		}
+	}
+
+	for _, nodeGroup := range []string{long, long + "b", strings.Repeat("a", 54) + "_" + strings.Repeat("b", 10), "___"} {
+		got := karpenterName(nodeGroup)
+		if len(got) > maxKubernetesNameLength || strings.HasPrefix(got, "-") || strings.HasSuffix(got, "-") ||
+			unsafeKubernetesNameChars.MatchString(got) {
+			t.Errorf("karpenterName(%q) = %q, which isn't a valid Kubernetes name", nodeGroup, got)
+		}
+	}
+	if karpenterName(long) == karpenterName(long+"b") {
+		t.Errorf("karpenterName returned the same name for different node groups")
+	}
+}
@@ -4,23 +4,18 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math"
-	"os/user"
-	"path/filepath"
-	"sort"
-	"strings"
 
 	"fyne.io/fyne/v2/data/binding"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
-	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
 	ec2instancesinfo "github.com/LeanerCloud/ec2-instances-info"
-	"gopkg.in/ini.v1"
 )
 
 type Launcher struct {
@@ -28,8 +23,18 @@ type Launcher struct {
 	CurrentRegion             string
 	GlobalServices            *globalServices
 	Connected                 bool
+	Identity                  *Identity
 	InstanceTypeData          *ec2instancesinfo.InstanceData
 	PricingIntervalMultiplier float64
+	ASGFilter                 *ASGFilter
+	ASGSort                   ASGSort
+	Horizon                   Horizon
+	Fee                       FeeConfig
+	Currency                  string
+	ExchangeRates             ExchangeRates
+	Discounts                 DiscountRules
+	FargateSpotDiscount       float64
+	GroupByCluster            bool
 
 	AutoSpottingCurrentTotalMonthlyCosts     binding.String
 	AutoSpottingProjectedMonthlyCosts        binding.String
@@ -37,14 +42,20 @@ type Launcher struct {
 	AutoSpottingProjectedSpotSavingsPercent  binding.String
 	AutoSpottingProjectedAutoSpottingCharges binding.String
 	AutoSpottingProjectedNetSavings          binding.String
+	FeeLabel                                 binding.String
+
+	AccountIdentity binding.String
 }
 
 type Region struct {
-	services         *services
-	AutoSpotting     *AutoSpotting
-	Launcher         *Launcher
-	name             string
-	instanceTypeData *ec2instancesinfo.InstanceData
+	services            *services
+	AutoSpotting        *AutoSpotting
+	ECSClusters         []*ECSCluster
+	StandaloneInstances []*StandaloneInstance
+	Fleets              []*Fleet
+	Launcher            *Launcher
+	name                string
+	instanceTypeData    *ec2instancesinfo.InstanceData
 }
 
 // TODO: remove the hardcoded region list
@@ -69,36 +80,25 @@ func (c *Launcher) AWSRegions() []string {
 	}
 }
 
-func (c *Launcher) ReadAWSProfiles() []string {
-	usr, _ := user.Current()
-	homeDir := usr.HomeDir
-	path := filepath.Join(homeDir, ".aws/config")
-
-	cfg, err := ini.Load(path)
-	if err != nil {
-		log.Printf("Fail to read AWS Credentials file: %v", err)
-		return []string{}
-	}
-
-	profiles := cfg.SectionStrings()[1:]
-
-	for i, profile := range profiles {
-		profiles[i] = strings.Replace(profile, "profile ", "", 1)
-	}
-
-	sort.Strings(profiles)
-	return profiles
-}
-
-func (c *Launcher) Connect(configOption config.LoadOptionsFunc) {
+func (c *Launcher) Connect(configOption config.LoadOptionsFunc) error {
 	mainRegion := "us-east-1"
 
+	c.Connected = false
+	c.setIdentity(nil)
+
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
 		configOption,
 		config.WithRegion(mainRegion),
 	)
 	if err != nil {
 		log.Printf("unable to load SDK config from profile , %v", err)
+		return fmt.Errorf("unable to load the AWS configuration: %w", err)
+	}
+
+	identity, err := validateCredentials(cfg)
+	if err != nil {
+		log.Println(err.Error())
+		return err
 	}
 
 	log.Println("Connecting global services in region", mainRegion)
@@ -106,6 +106,7 @@ func (c *Launcher) Connect(configOption config.LoadOptionsFunc) {
 	s := globalServices{
 		config:         cfg,
 		cloudformation: cloudformation.NewFromConfig(cfg),
+		iam:            iam.NewFromConfig(cfg),
 		s3:             s3.NewFromConfig(cfg),
 	}
 
@@ -121,6 +122,7 @@ func (c *Launcher) Connect(configOption config.LoadOptionsFunc) {
 		)
 		if err != nil {
 			log.Printf("unable to load SDK config from profile , %v", err)
+			return fmt.Errorf("unable to load the AWS configuration for region %s: %w", r, err)
 		}
 
 		log.Println("Connecting services in region", r)
@@ -129,12 +131,14 @@ func (c *Launcher) Connect(configOption config.LoadOptionsFunc) {
 			config:      cfg,
 			autoscaling: autoscaling.NewFromConfig(cfg),
 			ec2:         ec2.NewFromConfig(cfg),
+			ecs:         ecs.NewFromConfig(cfg),
 			//cfn:         cloudformation.NewFromConfig(cfg)
 		}
 
 		c.Regions[r] = &Region{
 			name:     r,
 			services: &s,
+			Launcher: c,
 			AutoSpotting: &AutoSpotting{
 				services: &s,
 			},
@@ -143,126 +147,109 @@ func (c *Launcher) Connect(configOption config.LoadOptionsFunc) {
 		c.Regions[r].AutoSpotting.region = c.Regions[r]
 
 	}
+	c.setIdentity(identity)
 	c.Connected = true
+	return nil
+}
+
+func (c *Launcher) setIdentity(identity *Identity) {
+	c.Identity = identity
+	if c.AccountIdentity != nil {
+		c.AccountIdentity.Set(identity.String())
+	}
 }
 
-func (c *Launcher) ConnectWithProfileAuth(profile string) {
+func (c *Launcher) ConnectWithProfileAuth(profile string) error {
 	co := config.WithSharedConfigProfile(profile)
-	c.Connect(co)
+	return c.Connect(co)
 }
 
-func (c *Launcher) ConnectWithStaticAuth(key, secret, token string) {
+func (c *Launcher) ConnectWithStaticAuth(key, secret, token string) error {
 	co := config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(key, secret, token))
-	c.Connect(co)
+	return c.Connect(co)
 }
 
 func (c *Launcher) SetRegion(region string) {
 	c.CurrentRegion = region
 }
 
+// SetPricingInterval sets one of the predefined horizons, falling back to
+// hourly pricing for unknown ones.
 func (c *Launcher) SetPricingInterval(s string) {
-	if s == "monthly" {
-		c.PricingIntervalMultiplier = 730.0
-
-	} else {
-		c.PricingIntervalMultiplier = 1.0
+	if err := c.SetHorizon(Horizon{Interval: s}); err != nil {
+		c.SetHorizon(Horizon{Interval: IntervalHourly})
 	}
 }
 
-func (c *Launcher) UpdateAutoSpottingTotals(region string) {
-	var currentTotalMonthlyCosts, projectedMonthlyCosts, projectedSpotSavings, projectedSpotSavingsPercent, projectedNetSavings float64
+// PricingInterval returns the label of the horizon used for the costs.
+func (c *Launcher) PricingInterval() string {
+	return c.Horizon.Label()
+}
 
-	if c.Regions == nil || c.Regions[region] == nil || c.Regions[region].AutoSpotting == nil || len(c.Regions[region].AutoSpotting.ASGs) == 0 {
-		return
-	}
+// Totals holds the aggregated costs and savings of a set of ASGs over the
+// selected horizon.
+type Totals struct {
+	CurrentCosts       float64 `json:"current_costs"`
+	ProjectedCosts     float64 `json:"projected_costs"`
+	SpotSavings        float64 `json:"spot_savings"`
+	SpotSavingsPercent float64 `json:"spot_savings_percent"`
+	Fee                float64 `json:"fee"`
+	NetSavings         float64 `json:"net_savings"`
+}
 
-	for _, asg := range c.Regions[region].AutoSpotting.ASGs {
+// AutoSpottingTotals aggregates the costs of the ASGs over the selected
+// horizon, using the selected fee model, in the selected currency.
+func (c *Launcher) AutoSpottingTotals(asgs []*ASG) Totals {
+	return totals(asgs, c.feeModel(), c.PricingIntervalMultiplier).converted(c.ExchangeRate())
+}
 
-		currentTotalMonthlyCosts += asg.HourlyCosts * 730
+func totals(asgs []*ASG, fee FeeModel, hours float64) Totals {
+	var t Totals
+
+	for _, asg := range asgs {
+
+		t.CurrentCosts += asg.HourlyCosts * hours
 		if !asg.Enabled {
-			projectedMonthlyCosts += asg.HourlyCosts * 730
+			t.ProjectedCosts += asg.HourlyCosts * hours
 			continue
 		}
-		projectedMonthlyCosts += asg.ProjectedCosts * 730
-		projectedSpotSavings += asg.ProjectedSavings * 730
+		t.ProjectedCosts += asg.ProjectedCosts * hours
+		t.SpotSavings += asg.ProjectedSavings * hours
 
 	}
 
-	projectedAutoSpottingCharges := math.Floor(projectedSpotSavings/7.3) * 0.73
-	projectedSpotSavingsPercent = projectedSpotSavings / currentTotalMonthlyCosts
-
-	projectedNetSavings = projectedSpotSavings - projectedAutoSpottingCharges
-
-	c.AutoSpottingCurrentTotalMonthlyCosts.Set(fmt.Sprintf("%.2f", currentTotalMonthlyCosts))
-	c.AutoSpottingProjectedMonthlyCosts.Set(fmt.Sprintf("%.2f", projectedMonthlyCosts))
-	c.AutoSpottingProjectedSpotSavings.Set(fmt.Sprintf("%.2f", projectedSpotSavings))
-	c.AutoSpottingProjectedSpotSavingsPercent.Set(fmt.Sprintf("%d%%", int(projectedSpotSavingsPercent*100)))
-	c.AutoSpottingProjectedAutoSpottingCharges.Set(fmt.Sprintf("%.2f", projectedAutoSpottingCharges))
-	c.AutoSpottingProjectedNetSavings.Set(fmt.Sprintf("%.2f", projectedNetSavings))
+	// Fees are charged monthly, so they're prorated to the horizon.
+	if hours > 0 {
+		t.Fee = fee.MonthlyFee(t.SpotSavings/hours*hoursPerMonth) * hours / hoursPerMonth
+	}
+	if t.CurrentCosts > 0 {
+		t.SpotSavingsPercent = t.SpotSavings / t.CurrentCosts * 100
+	}
 
+	t.NetSavings = t.SpotSavings - t.Fee
+	return t
 }
 
-func (c *Launcher) ApplyAutoSpottingTags() {
-	log.Printf("Appling tags for all ASGs")
-	if c.Regions == nil || c.CurrentRegion == "" || c.Regions[c.CurrentRegion] == nil || c.Regions[c.CurrentRegion].AutoSpotting == nil || len(c.Regions[c.CurrentRegion].AutoSpotting.ASGs) == 0 {
-		log.Printf("Appling tags for all ASGs failes nil checks")
+func (c *Launcher) UpdateAutoSpottingTotals(region string) {
+	if c.Regions == nil || c.Regions[region] == nil || c.Regions[region].AutoSpotting == nil || len(c.Regions[region].AutoSpotting.ASGs) == 0 {
 		return
 	}
 
-	as := c.Regions[c.CurrentRegion].AutoSpotting
-	for _, asg := range as.ASGs {
-		log.Printf("Appling tags for ASG %s", *asg.AutoScalingGroupName)
-
-		// ResourceId:         []string{*asg.AutoScalingGroupName},
-		// Tags:              tags,
-		// ResourceType:      autoscaling.ResourceTypeAutoScalingGroup,
-		// PropagateAtLaunch: aws.Bool(true),
-
-		resourceType := "auto-scaling-group"
-		propagate := false
-		var tags []types.Tag
-
-		if asg.Enabled || asg.EnabledTagExistedInitially {
-			key := "spot-enabled"
-			value := fmt.Sprintf("%t", asg.Enabled)
-			tags = append(tags, types.Tag{
-				Key:   &key,
-				Value: &value,
-			})
-		}
-
-		if asg.OnDemandNumber > 0 || asg.ODNumberTagExistedInitially {
-			key := "autospotting_min_on_demand_number"
-			value := fmt.Sprintf("%d", asg.OnDemandNumber)
-			tags = append(tags, types.Tag{
-				Key:   &key,
-				Value: &value,
-			})
-		}
-
-		if asg.OnDemandPercentage > 0 || asg.ODPercentageTagExistedInitially {
-			key := "autospotting_min_on_demand_percentage"
-			value := fmt.Sprintf("%.2f", float64(asg.OnDemandPercentage))
-			tags = append(tags, types.Tag{
-				Key:   &key,
-				Value: &value,
-			})
-		}
-
-		for i, _ := range tags {
-			tags[i].ResourceId = asg.AutoScalingGroupName
-			tags[i].ResourceType = &resourceType
-			tags[i].PropagateAtLaunch = &propagate
+	// The totals only cover the ASGs matching the current filter.
+	var asgs []*ASG
+	for _, asg := range c.Regions[region].AutoSpotting.ASGs {
+		if c.ASGFilter.Match(asg, c.CostMultiplier()) {
+			asgs = append(asgs, asg)
 		}
+	}
 
-		//	spew.Dump("Tags: %#v", tags)
+	t := c.AutoSpottingTotals(asgs)
 
-		_, err := as.services.autoscaling.CreateOrUpdateTags(context.TODO(), &autoscaling.CreateOrUpdateTagsInput{
-			Tags: tags,
-		})
+	c.AutoSpottingCurrentTotalMonthlyCosts.Set(fmt.Sprintf("%.2f", t.CurrentCosts))
+	c.AutoSpottingProjectedMonthlyCosts.Set(fmt.Sprintf("%.2f", t.ProjectedCosts))
+	c.AutoSpottingProjectedSpotSavings.Set(fmt.Sprintf("%.2f", t.SpotSavings))
+	c.AutoSpottingProjectedSpotSavingsPercent.Set(fmt.Sprintf("%d%%", int(t.SpotSavingsPercent)))
+	c.AutoSpottingProjectedAutoSpottingCharges.Set(fmt.Sprintf("%.2f", t.Fee))
+	c.AutoSpottingProjectedNetSavings.Set(fmt.Sprintf("%.2f", t.NetSavings))
 
-		if err != nil {
-			log.Printf("Could not create tags for AutoScalingGroup %s, error: %s", *asg.AutoScalingGroupName, err.Error())
-		}
-	}
 }
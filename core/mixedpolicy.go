@@ -0,0 +1,422 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+// Allocation strategies used by the native Spot conversion.
+const (
+	spotAllocationStrategy     = "price-capacity-optimized"
+	onDemandAllocationStrategy = "lowest-price"
+)
+
+// maxPolicyOverrides limits the instance types of the generated
+// MixedInstancesPolicy, to keep the overrides easy to review.
+const maxPolicyOverrides = 20
+
+// PolicyChange describes how a setting of the MixedInstancesPolicy of an ASG
+// changes when applying the native Spot conversion.
+type PolicyChange struct {
+	Setting  string
+	OldValue string
+	NewValue string
+}
+
+// Changed returns true when the setting gets a different value.
+func (c PolicyChange) Changed() bool {
+	return c.OldValue != c.NewValue
+}
+
+// ASGPolicyPlan is the MixedInstancesPolicy planned for an ASG. Skipped is set
+// with the reason when the ASG can't be converted.
+type ASGPolicyPlan struct {
+	ASG     *ASG
+	Changes []PolicyChange
+	Skipped string
+
+	policy *types.MixedInstancesPolicy
+}
+
+// Pending returns the settings that need to be changed.
+func (p ASGPolicyPlan) Pending() []PolicyChange {
+	var ret []PolicyChange
+	for _, change := range p.Changes {
+		if change.Changed() {
+			ret = append(ret, change)
+		}
+	}
+	return ret
+}
+
+// PolicyPlan is the dry-run of converting the ASGs of a region to Spot with a
+// native MixedInstancesPolicy, as an alternative to the AutoSpotting tags.
+// The savings of the converted ASGs are projected over the selected horizon
+// and in the selected currency, before any fee, both with the native policy
+// and with AutoSpotting, which apply the OnDemand percentage differently.
+type PolicyPlan struct {
+	Region              string
+	ASGs                []ASGPolicyPlan
+	NativeSavings       float64
+	AutoSpottingSavings float64
+}
+
+// PolicyApplyResult is the outcome of applying the policy plan of an ASG.
+type PolicyApplyResult struct {
+	ASGPolicyPlan
+	Status ApplyStatus
+	Err    error
+}
+
+// Failed returns a plan for retrying only the ASGs that failed.
+func (p *PolicyPlan) Failed(results []PolicyApplyResult) *PolicyPlan {
+	failed := PolicyPlan{Region: p.Region}
+	for _, r := range results {
+		if r.Status == ApplyStatusFailed {
+			failed.ASGs = append(failed.ASGs, r.ASGPolicyPlan)
+		}
+	}
+	return &failed
+}
+
+// launchTemplateSpecification returns the launch template used by the ASG,
+// either directly or through its current MixedInstancesPolicy.
+func (asg *ASG) launchTemplateSpecification() *types.LaunchTemplateSpecification {
+	if asg.LaunchTemplate != nil {
+		return asg.LaunchTemplate
+	}
+	if asg.MixedInstancesPolicy != nil && asg.MixedInstancesPolicy.LaunchTemplate != nil {
+		return asg.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification
+	}
+	return nil
+}
+
+// launchTemplateReference returns a copy of the launch template
+// specification with only its ID and version, since UpdateAutoScalingGroup
+// rejects specifications that set both the ID and the name. The name is only
+// kept when the ID isn't known.
+func launchTemplateReference(spec *types.LaunchTemplateSpecification) *types.LaunchTemplateSpecification {
+	if spec == nil {
+		return nil
+	}
+	ret := types.LaunchTemplateSpecification{
+		LaunchTemplateId: spec.LaunchTemplateId,
+		Version:          spec.Version,
+	}
+	if ret.LaunchTemplateId == nil {
+		ret.LaunchTemplateName = spec.LaunchTemplateName
+	}
+	return &ret
+}
+
+// policyReference returns a copy of the MixedInstancesPolicy with its launch
+// templates referenced by ID and version, as accepted by
+// UpdateAutoScalingGroup.
+func policyReference(mip *types.MixedInstancesPolicy) *types.MixedInstancesPolicy {
+	if mip == nil || mip.LaunchTemplate == nil {
+		return mip
+	}
+	ret := *mip
+	lt := *mip.LaunchTemplate
+	lt.LaunchTemplateSpecification = launchTemplateReference(lt.LaunchTemplateSpecification)
+	lt.Overrides = make([]types.LaunchTemplateOverrides, len(mip.LaunchTemplate.Overrides))
+	for i, o := range mip.LaunchTemplate.Overrides {
+		o.LaunchTemplateSpecification = launchTemplateReference(o.LaunchTemplateSpecification)
+		lt.Overrides[i] = o
+	}
+	ret.LaunchTemplate = &lt
+	return &ret
+}
+
+func launchTemplateString(spec *types.LaunchTemplateSpecification) string {
+	if spec == nil {
+		return ""
+	}
+	name := aws.ToString(spec.LaunchTemplateName)
+	if name == "" {
+		name = aws.ToString(spec.LaunchTemplateId)
+	}
+	return name + ":" + aws.ToString(spec.Version)
+}
+
+// policyOverrides diversifies the instance types of the ASG with the cheapest
+// Spot alternatives of each of them. The overrides of an existing
+// MixedInstancesPolicy are kept, so converting the ASG again doesn't keep
+// widening them, only with their launch templates referenced by ID.
+func (asg *ASG) policyOverrides() []types.LaunchTemplateOverrides {
+	if mip := asg.MixedInstancesPolicy; mip != nil && mip.LaunchTemplate != nil && len(mip.LaunchTemplate.Overrides) > 0 {
+		return policyReference(mip).LaunchTemplate.Overrides
+	}
+
+	var instanceTypes []string
+	add := func(instanceType string) {
+		if len(instanceTypes) == maxPolicyOverrides {
+			return
+		}
+		for _, t := range instanceTypes {
+			if t == instanceType {
+				return
+			}
+		}
+		instanceTypes = append(instanceTypes, instanceType)
+	}
+
+	for _, t := range asg.InstanceTypes {
+		add(t)
+	}
+	if asg.spotProduct != nil {
+		for _, t := range asg.InstanceTypes {
+			alternatives := asg.region.poolAlternatives(t, *asg.spotProduct)
+			for i := 0; i < maxSuggestedPools && i < len(alternatives); i++ {
+				add(alternatives[i])
+			}
+		}
+	}
+
+	var ret []types.LaunchTemplateOverrides
+	for _, t := range instanceTypes {
+		ret = append(ret, types.LaunchTemplateOverrides{InstanceType: aws.String(t)})
+	}
+	return ret
+}
+
+func overridesString(overrides []types.LaunchTemplateOverrides) string {
+	var ret []string
+	for _, o := range overrides {
+		switch {
+		case o.InstanceType != nil:
+			ret = append(ret, aws.ToString(o.InstanceType))
+		case o.InstanceRequirements != nil:
+			ret = append(ret, "instance requirements")
+		}
+	}
+	return strings.Join(ret, ", ")
+}
+
+func int32String(i *int32) string {
+	if i == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *i)
+}
+
+// nativeOnDemandCount returns the number of OnDemand instances kept by the
+// native policy of the ASG: the OnDemand base capacity, plus the OnDemand
+// percentage of the capacity above it, rounded up like AutoScaling does.
+func (asg *ASG) nativeOnDemandCount() int {
+	desired := int(aws.ToInt32(asg.DesiredCapacity))
+	base := int(asg.OnDemandNumber)
+	if base > desired {
+		base = desired
+	}
+	percentage := int(math.Round(asg.OnDemandPercentage))
+	return base + (percentage*(desired-base)+99)/100
+}
+
+// nativeHourlySavings returns the hourly savings of the ASG once converted
+// with the native policy.
+func (asg *ASG) nativeHourlySavings() float64 {
+	if asg.spotProduct == nil {
+		return 0
+	}
+	keepOnDemand := asg.nativeOnDemandCount()
+	var ret float64
+	for i, instance := range asg.Instances {
+		if i < keepOnDemand {
+			continue
+		}
+		pricing := asg.getHourlyPricing("cost", *instance.InstanceType, asg.region.name, *asg.spotProduct)
+		if pricing == nil {
+			continue
+		}
+		ret += pricing.OnDemand - pricing.SpotMin
+	}
+	return ret
+}
+
+// desiredPolicy maps the OnDemand number and percentage of the ASG to the
+// OnDemand base capacity and the OnDemand percentage above it.
+func (asg *ASG) desiredPolicy() (*types.MixedInstancesPolicy, error) {
+	if !asg.Enabled {
+		return nil, errors.New("not enabled for Spot")
+	}
+	spec := asg.launchTemplateSpecification()
+	if spec == nil {
+		return nil, errors.New("uses a launch configuration, migrate it to a launch template first")
+	}
+	overrides := asg.policyOverrides()
+	if len(overrides) == 0 {
+		return nil, errors.New("couldn't determine the instance types")
+	}
+
+	return &types.MixedInstancesPolicy{
+		LaunchTemplate: &types.LaunchTemplate{
+			LaunchTemplateSpecification: launchTemplateReference(spec),
+			Overrides:                   overrides,
+		},
+		InstancesDistribution: &types.InstancesDistribution{
+			OnDemandAllocationStrategy:          aws.String(onDemandAllocationStrategy),
+			OnDemandBaseCapacity:                aws.Int32(int32(asg.OnDemandNumber)),
+			OnDemandPercentageAboveBaseCapacity: aws.Int32(int32(math.Round(asg.OnDemandPercentage))),
+			SpotAllocationStrategy:              aws.String(spotAllocationStrategy),
+		},
+	}, nil
+}
+
+// policyChanges compares the current and desired policies of the ASG.
+func (asg *ASG) policyChanges(desired *types.MixedInstancesPolicy) []PolicyChange {
+	var current types.MixedInstancesPolicy
+	if asg.MixedInstancesPolicy != nil {
+		current = *asg.MixedInstancesPolicy
+	}
+	var currentOverrides []types.LaunchTemplateOverrides
+	if current.LaunchTemplate != nil {
+		currentOverrides = current.LaunchTemplate.Overrides
+	} else {
+		for _, t := range asg.InstanceTypes {
+			currentOverrides = append(currentOverrides, types.LaunchTemplateOverrides{InstanceType: aws.String(t)})
+		}
+	}
+	var dist types.InstancesDistribution
+	if current.InstancesDistribution != nil {
+		dist = *current.InstancesDistribution
+	}
+	newDist := desired.InstancesDistribution
+
+	// The desired policy keeps the launch template of the ASG, only referenced
+	// by ID, so it's displayed with the name from the current specification.
+	lt := launchTemplateString(asg.launchTemplateSpecification())
+
+	return []PolicyChange{
+		{"Launch template", lt, lt},
+		{"Instance types", overridesString(currentOverrides), overridesString(desired.LaunchTemplate.Overrides)},
+		{"OnDemand base capacity", int32String(dist.OnDemandBaseCapacity), int32String(newDist.OnDemandBaseCapacity)},
+		{"OnDemand % above base", int32String(dist.OnDemandPercentageAboveBaseCapacity), int32String(newDist.OnDemandPercentageAboveBaseCapacity)},
+		{"Spot allocation strategy", aws.ToString(dist.SpotAllocationStrategy), aws.ToString(newDist.SpotAllocationStrategy)},
+		{"OnDemand allocation strategy", aws.ToString(dist.OnDemandAllocationStrategy), aws.ToString(newDist.OnDemandAllocationStrategy)},
+	}
+}
+
+// PlanMixedInstancesPolicies computes the MixedInstancesPolicy of each
+// selected ASG of the current region from its OnDemand number and percentage,
+// without changing anything.
+func (c *Launcher) PlanMixedInstancesPolicies() (*PolicyPlan, error) {
+	if len(c.currentASGs()) == 0 {
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+
+	selected := c.SelectedASGs()
+	if len(selected) == 0 {
+		return nil, errors.New("no AutoScaling groups selected")
+	}
+
+	plan := PolicyPlan{Region: c.CurrentRegion}
+	for _, asg := range selected {
+		asgPlan := ASGPolicyPlan{ASG: asg}
+		if asg.IsManagedNodeGroup() {
+			asgPlan.Skipped = "EKS managed node group, change the node group capacity type instead"
+			plan.ASGs = append(plan.ASGs, asgPlan)
+			continue
+		}
+		policy, err := asg.desiredPolicy()
+		if err != nil {
+			asgPlan.Skipped = err.Error()
+		} else {
+			asgPlan.policy = policy
+			asgPlan.Changes = asg.policyChanges(policy)
+			plan.NativeSavings += asg.nativeHourlySavings() * c.CostMultiplier()
+			plan.AutoSpottingSavings += asg.ProjectedSavings * c.CostMultiplier()
+		}
+		plan.ASGs = append(plan.ASGs, asgPlan)
+	}
+	return &plan, nil
+}
+
+// ApplyMixedInstancesPolicies applies the policy plan with
+// UpdateAutoScalingGroup, after saving a snapshot of the previous launch
+// template and MixedInstancesPolicy of the ASGs that can be used for rolling
+// it back. It returns the result for each ASG from the plan.
+func (c *Launcher) ApplyMixedInstancesPolicies(plan *PolicyPlan) ([]PolicyApplyResult, error) {
+	if plan == nil || c.Regions == nil || c.Regions[plan.Region] == nil || c.Regions[plan.Region].AutoSpotting == nil {
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+
+	if _, err := c.snapshotPolicies(plan); err != nil {
+		return nil, fmt.Errorf("couldn't save the snapshot of the current policies, nothing was changed: %w", err)
+	}
+
+	return c.applyMixedInstancesPolicies(plan), nil
+}
+
+// RetryMixedInstancesPolicies applies the policy plan of the ASGs that failed
+// to apply before. It doesn't take a new snapshot, since the failed ASGs are
+// unchanged and already covered by the snapshot of the first attempt.
+func (c *Launcher) RetryMixedInstancesPolicies(plan *PolicyPlan) ([]PolicyApplyResult, error) {
+	if plan == nil || c.Regions == nil || c.Regions[plan.Region] == nil || c.Regions[plan.Region].AutoSpotting == nil {
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+	return c.applyMixedInstancesPolicies(plan), nil
+}
+
+func (c *Launcher) applyMixedInstancesPolicies(plan *PolicyPlan) []PolicyApplyResult {
+	as := c.Regions[plan.Region].AutoSpotting
+	results := make([]PolicyApplyResult, 0, len(plan.ASGs))
+	for _, asgPlan := range plan.ASGs {
+		results = append(results, as.applyPolicyPlan(asgPlan))
+	}
+	return results
+}
+
+func (a *AutoSpotting) applyPolicyPlan(asgPlan ASGPolicyPlan) PolicyApplyResult {
+	asg := asgPlan.ASG
+	result := PolicyApplyResult{ASGPolicyPlan: asgPlan}
+
+	if asgPlan.Skipped != "" || len(asgPlan.Pending()) == 0 {
+		log.Printf("No MixedInstancesPolicy changes needed for ASG %s", *asg.AutoScalingGroupName)
+		result.Status = ApplyStatusSkipped
+		return result
+	}
+
+	log.Printf("Applying the MixedInstancesPolicy of ASG %s", *asg.AutoScalingGroupName)
+
+	err := a.updatePolicy(*asg.AutoScalingGroupName, nil, asgPlan.policy)
+	if err != nil {
+		log.Printf("Could not update the MixedInstancesPolicy of AutoScalingGroup %s, error: %s", *asg.AutoScalingGroupName, err.Error())
+		result.Status = ApplyStatusFailed
+		result.Err = err
+		return result
+	}
+
+	// The loaded state keeps the full launch template specification, so the
+	// template is still displayed by name.
+	policy := *asgPlan.policy
+	lt := *policy.LaunchTemplate
+	lt.LaunchTemplateSpecification = asg.launchTemplateSpecification()
+	policy.LaunchTemplate = &lt
+
+	asg.LaunchTemplate = nil
+	asg.MixedInstancesPolicy = &policy
+	result.Status = ApplyStatusApplied
+	return result
+}
+
+// updatePolicy sets either the launch template or the MixedInstancesPolicy of
+// the ASG, replacing the other one.
+func (a *AutoSpotting) updatePolicy(name string, lt *types.LaunchTemplateSpecification, mip *types.MixedInstancesPolicy) error {
+	return retryThrottled(func() error {
+		_, err := a.services.autoscaling.UpdateAutoScalingGroup(context.TODO(), &autoscaling.UpdateAutoScalingGroupInput{
+			AutoScalingGroupName: aws.String(name),
+			LaunchTemplate:       lt,
+			MixedInstancesPolicy: mip,
+		})
+		return err
+	})
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+func TestNativeOnDemandCount(t *testing.T) {
+	tests := []struct {
+		desired    int32
+		number     int64
+		percentage float64
+		want       int
+	}{
+		{10, 0, 0, 0},
+		{10, 2, 0, 2},
+		{10, 0, 25, 3},
+		{10, 2, 25, 4},
+		{10, 2, 50, 6},
+		{10, 12, 50, 10},
+		{10, 0, 100, 10},
+		{0, 2, 50, 0},
+	}
+
+	for _, tt := range tests {
+		asg := ASG{
+			AutoScalingGroup:   types.AutoScalingGroup{DesiredCapacity: aws.Int32(tt.desired)},
+			OnDemandNumber:     tt.number,
+			OnDemandPercentage: tt.percentage,
+		}
+		if got := asg.nativeOnDemandCount(); got != tt.want {
+			t.Errorf("nativeOnDemandCount(desired %d, number %d, percentage %v) = %d, want %d",
+				tt.desired, tt.number, tt.percentage, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyOverridesReferenceLaunchTemplatesByID(t *testing.T) {
+	asg := ASG{AutoScalingGroup: types.AutoScalingGroup{
+		MixedInstancesPolicy: &types.MixedInstancesPolicy{
+			LaunchTemplate: &types.LaunchTemplate{
+				Overrides: []types.LaunchTemplateOverrides{
+					{InstanceType: aws.String("m5.large")},
+					{
+						InstanceType: aws.String("m6g.large"),
+						LaunchTemplateSpecification: &types.LaunchTemplateSpecification{
+							LaunchTemplateId:   aws.String("lt-0123"),
+							LaunchTemplateName: aws.String("arm"),
+							Version:            aws.String("$Latest"),
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	overrides := asg.policyOverrides()
+	if len(overrides) != 2 {
+		t.Fatalf("policyOverrides() returned %d overrides, want 2", len(overrides))
+	}
+	spec := overrides[1].LaunchTemplateSpecification
+	if aws.ToString(spec.LaunchTemplateId) != "lt-0123" || spec.LaunchTemplateName != nil || aws.ToString(spec.Version) != "$Latest" {
+		t.Errorf("override launch template = %+v, want only the ID and version", *spec)
+	}
+	if asg.MixedInstancesPolicy.LaunchTemplate.Overrides[1].LaunchTemplateSpecification.LaunchTemplateName == nil {
+		t.Error("policyOverrides() changed the current policy of the ASG")
+	}
+}
@@ -0,0 +1,574 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	astypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/smithy-go"
+)
+
+type Feature string
+
+const (
+	FeatureEstimate     Feature = "estimate"
+	FeatureTagApply     Feature = "tag-apply"
+	FeatureEBSOptimizer Feature = "ebs-optimizer"
+	FeatureMultiAccount Feature = "multi-account"
+	FeatureNativeApply  Feature = "native-apply"
+)
+
+// Features lists all the features in the order they're displayed, along with
+// their descriptions.
+var Features = []struct {
+	Feature     Feature
+	Description string
+}{
+	{FeatureEstimate, "Read-only savings estimate"},
+	{FeatureTagApply, "Apply the AutoSpotting configuration tags"},
+	{FeatureNativeApply, "Apply the native MixedInstancesPolicy"},
+	{FeatureEBSOptimizer, "EBS optimizer"},
+	{FeatureMultiAccount, "Multi-account access"},
+}
+
+// TemplateFeatures are the features of the CloudFormation template shipped
+// with the repository, covering both apply modes. They're also the default of
+// the iam-policy command.
+var TemplateFeatures = []Feature{FeatureEstimate, FeatureTagApply, FeatureNativeApply}
+
+// probeASGName returns a random ASG name for the probes of mutating
+// AutoScaling calls, after checking that no such group exists. An authorized
+// call then fails validation without changing anything, while an unauthorized
+// one is rejected with AccessDenied.
+func probeASGName(ctx context.Context, s *services) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	name := "savings-estimator-permission-probe-" + hex.EncodeToString(b)
+
+	output, err := s.autoscaling.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []string{name},
+	})
+	if err != nil {
+		// Not wrapped, the probe result is unknown rather than denied.
+		return "", fmt.Errorf("couldn't check the probe AutoScaling group %s: %v", name, err)
+	}
+	if len(output.AutoScalingGroups) > 0 {
+		return "", fmt.Errorf("the probe AutoScaling group %s already exists", name)
+	}
+	return name, nil
+}
+
+type permissionProbe func(ctx context.Context, g *globalServices, s *services) error
+
+// Permission is an AWS API action called by the estimator, along with the
+// features that need it and a side-effect free way to check if it's allowed.
+type Permission struct {
+	Action   string
+	Features []Feature
+	// Optional permissions improve the experience but aren't required.
+	Optional bool
+	// Global permissions are for services that aren't regional, like IAM.
+	Global bool
+	// Resource scopes down the permission in the generated policies, it
+	// defaults to "*".
+	Resource string
+	probe    permissionProbe
+}
+
+func (p Permission) resource() string {
+	if p.Resource == "" {
+		return "*"
+	}
+	return p.Resource
+}
+
+func (p Permission) hasFeature(features []Feature) bool {
+	for _, f := range p.Features {
+		for _, wanted := range features {
+			if f == wanted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var permissions = []Permission{
+	{
+		Action:   "autoscaling:DescribeAutoScalingGroups",
+		Features: []Feature{FeatureEstimate, FeatureTagApply, FeatureNativeApply},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.autoscaling.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+				MaxRecords: aws.Int32(1),
+			})
+			return err
+		},
+	},
+	{
+		Action:   "autoscaling:DescribeLaunchConfigurations",
+		Features: []Feature{FeatureEstimate},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.autoscaling.DescribeLaunchConfigurations(ctx, &autoscaling.DescribeLaunchConfigurationsInput{
+				MaxRecords: aws.Int32(1),
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ec2:DescribeLaunchTemplateVersions",
+		Features: []Feature{FeatureEstimate},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ec2.DescribeLaunchTemplateVersions(ctx, &ec2.DescribeLaunchTemplateVersionsInput{
+				DryRun:   aws.Bool(true),
+				Versions: []string{"$Latest"},
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ec2:DescribeImages",
+		Features: []Feature{FeatureEstimate},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ec2.DescribeImages(ctx, &ec2.DescribeImagesInput{
+				DryRun: aws.Bool(true),
+				Owners: []string{"self"},
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ec2:DescribeInstances",
+		Features: []Feature{FeatureEstimate},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ec2.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ec2:DescribeSpotFleetRequests",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ec2.DescribeSpotFleetRequests(ctx, &ec2.DescribeSpotFleetRequestsInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ec2:DescribeFleets",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ec2.DescribeFleets(ctx, &ec2.DescribeFleetsInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ecs:ListClusters",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ecs.ListClusters(ctx, &ecs.ListClustersInput{
+				MaxResults: aws.Int32(1),
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ecs:DescribeClusters",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ecs.DescribeClusters(ctx, &ecs.DescribeClustersInput{})
+			return err
+		},
+	},
+	{
+		Action:   "ecs:DescribeCapacityProviders",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ecs.DescribeCapacityProviders(ctx, &ecs.DescribeCapacityProvidersInput{
+				MaxResults: aws.Int32(1),
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ecs:ListServices",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+	},
+	{
+		Action:   "ecs:DescribeServices",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+	},
+	{
+		Action:   "ecs:DescribeTaskDefinition",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+	},
+	{
+		Action:   "autoscaling:CreateOrUpdateTags",
+		Features: []Feature{FeatureTagApply},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			name, err := probeASGName(ctx, s)
+			if err != nil {
+				return err
+			}
+			_, err = s.autoscaling.CreateOrUpdateTags(ctx, &autoscaling.CreateOrUpdateTagsInput{
+				Tags: []astypes.Tag{{
+					Key:               aws.String("spot-enabled"),
+					Value:             aws.String("false"),
+					ResourceId:        aws.String(name),
+					ResourceType:      aws.String("auto-scaling-group"),
+					PropagateAtLaunch: aws.Bool(false),
+				}},
+			})
+			return err
+		},
+	},
+	{
+		Action:   "autoscaling:DeleteTags",
+		Features: []Feature{FeatureTagApply},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			name, err := probeASGName(ctx, s)
+			if err != nil {
+				return err
+			}
+			_, err = s.autoscaling.DeleteTags(ctx, &autoscaling.DeleteTagsInput{
+				Tags: []astypes.Tag{{
+					Key:          aws.String("spot-enabled"),
+					ResourceId:   aws.String(name),
+					ResourceType: aws.String("auto-scaling-group"),
+				}},
+			})
+			return err
+		},
+	},
+	{
+		Action:   "autoscaling:UpdateAutoScalingGroup",
+		Features: []Feature{FeatureNativeApply},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			name, err := probeASGName(ctx, s)
+			if err != nil {
+				return err
+			}
+			_, err = s.autoscaling.UpdateAutoScalingGroup(ctx, &autoscaling.UpdateAutoScalingGroupInput{
+				AutoScalingGroupName: aws.String(name),
+			})
+			return err
+		},
+	},
+	{
+		// Needed for setting the launch template of the MixedInstancesPolicy.
+		Action:   "ec2:RunInstances",
+		Features: []Feature{FeatureNativeApply},
+	},
+	{
+		// Needed when the launch template sets an instance profile.
+		Action:   "iam:PassRole",
+		Features: []Feature{FeatureNativeApply},
+		Optional: true,
+		Global:   true,
+	},
+	{
+		Action:   "iam:ListAccountAliases",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+		Global:   true,
+		probe: func(ctx context.Context, g *globalServices, _ *services) error {
+			_, err := g.iam.ListAccountAliases(ctx, &iam.ListAccountAliasesInput{})
+			return err
+		},
+	},
+	{
+		Action:   "iam:SimulatePrincipalPolicy",
+		Features: []Feature{FeatureEstimate},
+		Optional: true,
+		Global:   true,
+	},
+	{
+		Action:   "ec2:DescribeVolumes",
+		Features: []Feature{FeatureEBSOptimizer},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ec2.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ec2:DescribeVolumesModifications",
+		Features: []Feature{FeatureEBSOptimizer},
+		probe: func(ctx context.Context, _ *globalServices, s *services) error {
+			_, err := s.ec2.DescribeVolumesModifications(ctx, &ec2.DescribeVolumesModificationsInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		Action:   "ec2:ModifyVolume",
+		Features: []Feature{FeatureEBSOptimizer},
+	},
+	{
+		Action:   "organizations:ListAccounts",
+		Features: []Feature{FeatureMultiAccount},
+		Global:   true,
+	},
+	{
+		Action:   "sts:AssumeRole",
+		Features: []Feature{FeatureMultiAccount},
+		Global:   true,
+		Resource: "arn:" + partitionVariable + ":iam::*:role/" + IAMRoleName,
+	},
+}
+
+// Permissions returns the registry of the AWS API actions used by the
+// estimator.
+func Permissions() []Permission {
+	return permissions
+}
+
+type PermissionStatus string
+
+const (
+	PermissionAllowed PermissionStatus = "allowed"
+	PermissionDenied  PermissionStatus = "denied"
+	PermissionUnknown PermissionStatus = "unknown"
+)
+
+type PermissionCheck struct {
+	Permission
+	Status PermissionStatus
+	// Method is either "simulation" or "dry-run", depending on how the
+	// permission was checked.
+	Method string
+	Detail string
+}
+
+// PermissionReport holds the permission checks for a region, or for the
+// global services when Region is "global".
+type PermissionReport struct {
+	Region string
+	Checks []PermissionCheck
+}
+
+func (r PermissionReport) Denied() []PermissionCheck {
+	var denied []PermissionCheck
+	for _, check := range r.Checks {
+		if check.Status == PermissionDenied {
+			denied = append(denied, check)
+		}
+	}
+	return denied
+}
+
+// CheckPermissions runs the permission preflight of the given features for
+// the given regions. It uses IAM policy simulation when we're allowed to do
+// it, and falls back to dry-run probes otherwise.
+func (c *Launcher) CheckPermissions(regions []string, features ...Feature) ([]PermissionReport, error) {
+	if !c.Connected || c.GlobalServices == nil || c.Identity == nil {
+		return nil, errors.New("missing credentials")
+	}
+
+	ctx := context.TODO()
+	reports := []PermissionReport{c.checkGlobalPermissions(ctx, features)}
+
+	for _, region := range regions {
+		r, ok := c.Regions[region]
+		if !ok {
+			continue
+		}
+
+		report := PermissionReport{Region: region}
+		regional := regionalPermissions(features)
+		simulated, err := c.simulatePermissions(ctx, region, regional)
+		if err != nil {
+			log.Printf("IAM policy simulation failed in %s, falling back to dry-run probes: %v", region, err)
+		}
+
+		for _, p := range regional {
+			if check, ok := simulated[p.Action]; ok {
+				report.Checks = append(report.Checks, check)
+				continue
+			}
+			report.Checks = append(report.Checks, probePermission(ctx, p, c.GlobalServices, r.services))
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func regionalPermissions(features []Feature) []Permission {
+	var ret []Permission
+	for _, p := range permissions {
+		if !p.Global && p.hasFeature(features) {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+func (c *Launcher) checkGlobalPermissions(ctx context.Context, features []Feature) PermissionReport {
+	report := PermissionReport{Region: "global"}
+
+	var global []Permission
+	for _, p := range permissions {
+		if p.Global && p.hasFeature(features) {
+			global = append(global, p)
+		}
+	}
+
+	simulated, err := c.simulatePermissions(ctx, "", global)
+	for _, p := range global {
+		if check, ok := simulated[p.Action]; ok {
+			report.Checks = append(report.Checks, check)
+			continue
+		}
+
+		if p.Action == "iam:SimulatePrincipalPolicy" && err != nil {
+			report.Checks = append(report.Checks, PermissionCheck{
+				Permission: p,
+				Status:     classifyProbeError(err),
+				Method:     "simulation",
+				Detail:     err.Error(),
+			})
+			continue
+		}
+		report.Checks = append(report.Checks, probePermission(ctx, p, c.GlobalServices, nil))
+	}
+	return report
+}
+
+// simulatePermissions evaluates the IAM policies of the connected principal
+// for the given permissions. An empty region skips the aws:RequestedRegion
+// context, which is what we want for global services.
+func (c *Launcher) simulatePermissions(ctx context.Context, region string, perms []Permission) (map[string]PermissionCheck, error) {
+	principal, err := policySourceARN(c.Identity.ARN)
+	if err != nil {
+		return nil, err
+	}
+
+	byAction := map[string]Permission{}
+	actions := []string{}
+	for _, p := range perms {
+		byAction[p.Action] = p
+		actions = append(actions, p.Action)
+	}
+
+	input := &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal),
+		ActionNames:     actions,
+	}
+	if region != "" {
+		input.ContextEntries = []iamtypes.ContextEntry{{
+			ContextKeyName:   aws.String("aws:RequestedRegion"),
+			ContextKeyType:   iamtypes.ContextKeyTypeEnumString,
+			ContextKeyValues: []string{region},
+		}}
+	}
+
+	ret := map[string]PermissionCheck{}
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(c.GlobalServices.iam, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range output.EvaluationResults {
+			action := aws.ToString(result.EvalActionName)
+			check := PermissionCheck{
+				Permission: byAction[action],
+				Status:     PermissionDenied,
+				Method:     "simulation",
+				Detail:     string(result.EvalDecision),
+			}
+			if result.EvalDecision == iamtypes.PolicyEvaluationDecisionTypeAllowed {
+				check.Status = PermissionAllowed
+			}
+			ret[action] = check
+		}
+	}
+	return ret, nil
+}
+
+var assumedRoleARN = regexp.MustCompile(`^arn:(aws[a-z-]*):sts::(\d+):assumed-role/([^/]+)/.+$`)
+
+// policySourceARN converts the caller identity ARN into an ARN accepted by
+// the IAM policy simulator. Assumed role sessions are mapped to their role,
+// which assumes the role has the default "/" path.
+func policySourceARN(arn string) (string, error) {
+	if m := assumedRoleARN.FindStringSubmatch(arn); m != nil {
+		return fmt.Sprintf("arn:%s:iam::%s:role/%s", m[1], m[2], m[3]), nil
+	}
+
+	if strings.Contains(arn, ":iam::") && !strings.HasSuffix(arn, ":root") {
+		return arn, nil
+	}
+
+	return "", fmt.Errorf("can't simulate the IAM policies of %s", arn)
+}
+
+func probePermission(ctx context.Context, p Permission, g *globalServices, s *services) PermissionCheck {
+	check := PermissionCheck{
+		Permission: p,
+		Status:     PermissionUnknown,
+		Method:     "dry-run",
+	}
+
+	if p.probe == nil {
+		check.Detail = "no side-effect free probe available"
+		return check
+	}
+
+	err := p.probe(ctx, g, s)
+	check.Status = classifyProbeError(err)
+	if err != nil {
+		check.Detail = err.Error()
+	}
+	return check
+}
+
+// classifyProbeError interprets the outcome of a dry-run probe. EC2 reports
+// a successful dry run as a DryRunOperation error, and our probes for
+// mutating calls expect a validation error about the missing resource.
+func classifyProbeError(err error) PermissionStatus {
+	if err == nil {
+		return PermissionAllowed
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return PermissionUnknown
+	}
+
+	switch apiErr.ErrorCode() {
+	case "DryRunOperation", "ValidationError":
+		return PermissionAllowed
+	case "UnauthorizedOperation", "AccessDenied", "AccessDeniedException", "UnauthorizedAccess":
+		return PermissionDenied
+	}
+	return PermissionUnknown
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+const policySnapshotVersion = 1
+
+// ASGPolicySnapshot holds the launch template or MixedInstancesPolicy of an
+// ASG as it was before applying the native Spot conversion.
+type ASGPolicySnapshot struct {
+	Name                 string                             `json:"name"`
+	LaunchTemplate       *types.LaunchTemplateSpecification `json:"launch_template,omitempty"`
+	MixedInstancesPolicy *types.MixedInstancesPolicy        `json:"mixed_instances_policy,omitempty"`
+}
+
+// PolicySnapshot is the state of the launch templates and MixedInstancesPolicy
+// of the ASGs from a region, persisted on disk before converting them so they
+// can be rolled back.
+type PolicySnapshot struct {
+	Version   int                 `json:"version"`
+	Timestamp time.Time           `json:"timestamp"`
+	AccountID string              `json:"account_id"`
+	Region    string              `json:"region"`
+	ASGs      []ASGPolicySnapshot `json:"asgs"`
+
+	Path string `json:"-"`
+}
+
+func (s *PolicySnapshot) String() string {
+	return fmt.Sprintf("%s (%d AutoScaling groups)", s.Timestamp.Local().Format("2006-01-02 15:04:05"), len(s.ASGs))
+}
+
+func (c *Launcher) snapshotPolicies(plan *PolicyPlan) (*PolicySnapshot, error) {
+	if c.Identity == nil || c.Regions == nil || c.Regions[plan.Region] == nil || c.Regions[plan.Region].AutoSpotting == nil {
+		return nil, errors.New("missing credentials")
+	}
+
+	snapshot := PolicySnapshot{
+		Version:   policySnapshotVersion,
+		Timestamp: time.Now().UTC(),
+		AccountID: c.Identity.AccountID,
+		Region:    plan.Region,
+	}
+
+	// Only the ASGs that are about to change are saved, with their policies
+	// read again from AWS in case they changed since they were loaded.
+	var names []string
+	for _, asgPlan := range plan.ASGs {
+		if asgPlan.Skipped == "" && len(asgPlan.Pending()) > 0 {
+			names = append(names, *asgPlan.ASG.AutoScalingGroupName)
+		}
+	}
+
+	current, err := c.Regions[plan.Region].AutoSpotting.describeASGs(names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		asg, ok := current[name]
+		if !ok {
+			log.Printf("AutoScaling group %s no longer exists, leaving it out of the snapshot", name)
+			continue
+		}
+		snapshot.ASGs = append(snapshot.ASGs, ASGPolicySnapshot{
+			Name:                 name,
+			LaunchTemplate:       asg.LaunchTemplate,
+			MixedInstancesPolicy: asg.MixedInstancesPolicy,
+		})
+	}
+
+	if len(snapshot.ASGs) == 0 {
+		return &snapshot, nil
+	}
+
+	dir, err := dataDir("policy-snapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Path, err = writeSnapshot(dir, snapshot.Timestamp, snapshot.AccountID, snapshot.Region, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Saved the MixedInstancesPolicy snapshot to", snapshot.Path)
+	return &snapshot, nil
+}
+
+// ListPolicySnapshots returns the policy snapshots of the current account and
+// region, newest first.
+func (c *Launcher) ListPolicySnapshots() ([]*PolicySnapshot, error) {
+	if c.Identity == nil {
+		return nil, errors.New("missing credentials")
+	}
+
+	dir, err := dataDir("policy-snapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*PolicySnapshot
+	for _, f := range files {
+		if !strings.HasSuffix(f, fmt.Sprintf("-%s-%s.json", c.Identity.AccountID, c.CurrentRegion)) {
+			continue
+		}
+
+		data, err := os.ReadFile(f)
+		if err != nil {
+			log.Printf("Couldn't read snapshot %s: %v", f, err)
+			continue
+		}
+
+		var snapshot PolicySnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			log.Printf("Couldn't parse snapshot %s: %v", f, err)
+			continue
+		}
+		snapshot.Path = f
+		ret = append(ret, &snapshot)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Timestamp.After(ret[j].Timestamp)
+	})
+	return ret, nil
+}
+
+// RollbackMixedInstancesPolicies restores the launch template or the
+// MixedInstancesPolicy of the selected ASGs from the snapshot.
+func (c *Launcher) RollbackMixedInstancesPolicies(snapshot *PolicySnapshot) error {
+	if c.Identity == nil || c.Regions == nil || c.Regions[snapshot.Region] == nil {
+		return errors.New("missing credentials")
+	}
+
+	if snapshot.AccountID != c.Identity.AccountID {
+		return fmt.Errorf("the snapshot was taken in account %s, but we're connected to %s",
+			snapshot.AccountID, c.Identity.AccountID)
+	}
+
+	as := c.Regions[snapshot.Region].AutoSpotting
+	selected := map[string]*ASG{}
+	for _, asg := range as.ASGs {
+		if asg.Selected {
+			selected[*asg.AutoScalingGroupName] = asg
+		}
+	}
+
+	var failed []string
+	for _, s := range snapshot.ASGs {
+		asg, ok := selected[s.Name]
+		if !ok {
+			log.Printf("Skipping the rollback of ASG %s, which isn't selected", s.Name)
+			continue
+		}
+		if s.LaunchTemplate == nil && s.MixedInstancesPolicy == nil {
+			log.Printf("Skipping the rollback of ASG %s, the snapshot has no launch template", s.Name)
+			continue
+		}
+
+		log.Printf("Rolling back the MixedInstancesPolicy of ASG %s", s.Name)
+
+		if err := as.updatePolicy(s.Name, launchTemplateReference(s.LaunchTemplate), policyReference(s.MixedInstancesPolicy)); err != nil {
+			log.Printf("Could not roll back the MixedInstancesPolicy of AutoScalingGroup %s, error: %s", s.Name, err.Error())
+			failed = append(failed, s.Name)
+			continue
+		}
+
+		asg.LaunchTemplate = s.LaunchTemplate
+		asg.MixedInstancesPolicy = s.MixedInstancesPolicy
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("couldn't roll back the MixedInstancesPolicy of %d AutoScaling groups: %s",
+			len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"regexp"
+	"runtime/debug"
+	"time"
+)
+
+const pricingModule = "github.com/LeanerCloud/ec2-instances-info"
+
+var pseudoVersionTime = regexp.MustCompile(`[.-]([0-9]{14})-[0-9a-f]{12}$`)
+
+// PricingSource describes the pricing data used for the estimates. The data
+// is bundled with the ec2-instances-info module, so its age is given by the
+// date of the module version.
+type PricingSource struct {
+	Name    string    `json:"name"`
+	Version string    `json:"version"`
+	Date    time.Time `json:"date"`
+}
+
+func (p PricingSource) String() string {
+	if p.Date.IsZero() {
+		return p.Name + " " + p.Version
+	}
+	return p.Name + " " + p.Version + " (" + p.Date.Format("2006-01-02") + ")"
+}
+
+// AgeDays returns the age of the pricing data in days, or -1 if unknown.
+func (p PricingSource) AgeDays(now time.Time) int {
+	if p.Date.IsZero() {
+		return -1
+	}
+	return int(now.Sub(p.Date).Hours() / 24)
+}
+
+// pricingSource determines the version of the pricing data from the build
+// information of the binary.
+func pricingSource() PricingSource {
+	ret := PricingSource{
+		Name:    pricingModule,
+		Version: "unknown",
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ret
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path != pricingModule {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		ret.Version = dep.Version
+		if m := pseudoVersionTime.FindStringSubmatch(dep.Version); m != nil {
+			ret.Date, _ = time.Parse("20060102150405", m[1])
+		}
+	}
+	return ret
+}
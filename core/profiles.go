@@ -0,0 +1,144 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+type CredentialType string
+
+const (
+	CredentialTypeStatic            CredentialType = "static"
+	CredentialTypeSSO               CredentialType = "SSO"
+	CredentialTypeAssumeRole        CredentialType = "assume-role"
+	CredentialTypeCredentialProcess CredentialType = "credential_process"
+	CredentialTypeUnknown           CredentialType = "unknown"
+)
+
+// AWSProfile is a named profile found in the AWS CLI/SDK shared config or
+// credentials files.
+type AWSProfile struct {
+	Name           string
+	CredentialType CredentialType
+	keys           map[string]string
+}
+
+// String returns the profile name annotated with its credential type, as
+// displayed in the profile picker.
+func (p AWSProfile) String() string {
+	return fmt.Sprintf("%s (%s)", p.Name, p.CredentialType)
+}
+
+func awsSharedFilePath(envVar, defaultName string) string {
+	if path := os.Getenv(envVar); path != "" {
+		return path
+	}
+
+	usr, err := user.Current()
+	if err != nil {
+		log.Printf("Couldn't determine the home directory: %v", err)
+		return ""
+	}
+	return filepath.Join(usr.HomeDir, ".aws", defaultName)
+}
+
+// ReadAWSProfiles merges the profiles from the shared config and credentials
+// files, honoring the AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE
+// environment overrides.
+func (c *Launcher) ReadAWSProfiles() []AWSProfile {
+	found := map[string]*AWSProfile{}
+
+	readSections := func(path string, isConfigFile bool) {
+		if path == "" {
+			return
+		}
+		if _, err := os.Stat(path); err != nil {
+			log.Printf("Skipping AWS shared file %s: %v", path, err)
+			return
+		}
+
+		cfg, err := ini.Load(path)
+		if err != nil {
+			log.Printf("Fail to read AWS shared file %s: %v", path, err)
+			return
+		}
+
+		for _, section := range cfg.Sections() {
+			name, ok := profileName(section.Name(), isConfigFile)
+			if !ok {
+				continue
+			}
+
+			p, exists := found[name]
+			if !exists {
+				p = &AWSProfile{Name: name, keys: map[string]string{}}
+				found[name] = p
+			}
+			for _, k := range section.Keys() {
+				p.keys[k.Name()] = k.Value()
+			}
+		}
+	}
+
+	readSections(awsSharedFilePath("AWS_CONFIG_FILE", "config"), true)
+	readSections(awsSharedFilePath("AWS_SHARED_CREDENTIALS_FILE", "credentials"), false)
+
+	profiles := make([]AWSProfile, 0, len(found))
+	for _, p := range found {
+		p.CredentialType = credentialType(p.keys)
+		profiles = append(profiles, *p)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
+// profileName maps an ini section name to a profile name. The config file
+// prefixes all profiles except "default" with "profile ", while the
+// credentials file uses bare names. Other config sections such as
+// sso-session or services aren't profiles.
+func profileName(section string, isConfigFile bool) (string, bool) {
+	if section == ini.DefaultSection {
+		return "", false
+	}
+
+	if !isConfigFile {
+		return section, true
+	}
+
+	if section == "default" {
+		return section, true
+	}
+
+	if strings.HasPrefix(section, "profile ") {
+		return strings.TrimSpace(strings.TrimPrefix(section, "profile ")), true
+	}
+
+	return "", false
+}
+
+// credentialType follows the precedence used by the AWS SDK when resolving
+// the credentials of a profile: static keys, then SSO, then an external
+// credential process. A role_arn assumes a role on top of any of them.
+func credentialType(keys map[string]string) CredentialType {
+	switch {
+	case keys["role_arn"] != "":
+		return CredentialTypeAssumeRole
+	case keys["aws_access_key_id"] != "" && keys["aws_secret_access_key"] != "":
+		return CredentialTypeStatic
+	case keys["sso_session"] != "" || keys["sso_start_url"] != "":
+		return CredentialTypeSSO
+	case keys["credential_process"] != "":
+		return CredentialTypeCredentialProcess
+	}
+	return CredentialTypeUnknown
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+//go:embed report.html.tmpl
+var reportTemplateText string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"money": func(f float64) string { return fmt.Sprintf("%.2f", f) },
+	"join":  strings.Join,
+}).Parse(reportTemplateText))
+
+const (
+	reportTopASGs       = 10
+	reportChartASGs     = 20
+	reportChartWidth    = 800
+	reportChartLabels   = 220
+	reportChartRow      = 30
+	reportStalePricing  = 90
+	reportRiskASGs      = 5
+	reportMaxNameLength = 32
+)
+
+type reportKPI struct {
+	Label string
+	Value string
+}
+
+type reportBar struct {
+	Name           string
+	Y              int
+	Costs          float64
+	ProjectedCosts float64
+	CostsWidth     float64
+	ProjectedWidth float64
+}
+
+// report is the content shared by the HTML and PDF reports.
+type report struct {
+	*Estimate
+	Details     [][]string
+	KPIs        []reportKPI
+	Bars        []reportBar
+	ChartHeight int
+	ChartWidth  int
+	ChartLabels int
+	Top         []ASGEstimate
+	Risks       []string
+	Assumptions [][]string
+	Currency    string
+}
+
+func (e *Estimate) report() *report {
+	r := report{
+		Estimate:    e,
+		Details:     e.metadataRows(),
+		ChartWidth:  reportChartWidth,
+		ChartLabels: reportChartLabels,
+		Risks:       e.riskNotes(),
+		Assumptions: e.assumptionRows(),
+		Currency:    e.currencyNote(),
+	}
+
+	interval, currency := e.Metadata.PricingInterval, e.Metadata.Currency
+	r.KPIs = []reportKPI{
+		{"Current " + interval + " costs", fmt.Sprintf("%.2f %s", e.Totals.CurrentCosts, currency)},
+		{"Projected " + interval + " costs", fmt.Sprintf("%.2f %s", e.Totals.ProjectedCosts, currency)},
+		{"Spot savings", fmt.Sprintf("%.2f %s (%.0f%%)", e.Totals.SpotSavings, currency, e.Totals.SpotSavingsPercent)},
+		{"Net " + interval + " savings", fmt.Sprintf("%.2f %s", e.Totals.NetSavings, currency)},
+	}
+
+	bySavings := append([]ASGEstimate{}, e.ASGs...)
+	sort.SliceStable(bySavings, func(i, j int) bool {
+		return bySavings[i].Savings > bySavings[j].Savings
+	})
+	for _, a := range bySavings {
+		if len(r.Top) == reportTopASGs || a.Savings <= 0 {
+			break
+		}
+		r.Top = append(r.Top, a)
+	}
+
+	byCosts := append([]ASGEstimate{}, e.ASGs...)
+	sort.SliceStable(byCosts, func(i, j int) bool {
+		return byCosts[i].Costs > byCosts[j].Costs
+	})
+	if len(byCosts) > reportChartASGs {
+		byCosts = byCosts[:reportChartASGs]
+	}
+
+	var max float64
+	for _, a := range byCosts {
+		if a.Costs > max {
+			max = a.Costs
+		}
+		if a.ProjectedCosts > max {
+			max = a.ProjectedCosts
+		}
+	}
+
+	barArea := float64(reportChartWidth - reportChartLabels - 80)
+	for i, a := range byCosts {
+		bar := reportBar{
+			Name:           truncate(a.Name, reportMaxNameLength),
+			Y:              i * reportChartRow,
+			Costs:          a.Costs,
+			ProjectedCosts: a.ProjectedCosts,
+		}
+		if max > 0 {
+			bar.CostsWidth = a.Costs / max * barArea
+			bar.ProjectedWidth = a.ProjectedCosts / max * barArea
+		}
+		r.Bars = append(r.Bars, bar)
+	}
+	r.ChartHeight = len(r.Bars)*reportChartRow + 10
+
+	return &r
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
+
+// riskNotes lists the configurations that may cause disruptions when running
+// on Spot instances, or make the estimate less accurate.
+func (e *Estimate) riskNotes() []string {
+	var ret []string
+
+	if e.Metadata.PricingAgeDays > reportStalePricing {
+		ret = append(ret, fmt.Sprintf("The pricing data is %d days old, current prices may differ.",
+			e.Metadata.PricingAgeDays))
+	}
+
+	var fullSpot, singleType, singleInstance, unpriced, nodeGroups []string
+	for _, a := range e.ASGs {
+		if a.Instances > 0 && a.Costs == 0 {
+			unpriced = append(unpriced, a.Name)
+		}
+		if !a.Enabled {
+			continue
+		}
+		if a.EKSCluster != "" && a.EKSNodeGroup != "" {
+			nodeGroups = append(nodeGroups, a.Name)
+		}
+		if a.OnDemandNumber == 0 && a.OnDemandPercentage == 0 {
+			fullSpot = append(fullSpot, a.Name)
+		}
+		if len(a.InstanceTypes) < 2 {
+			singleType = append(singleType, a.Name)
+		}
+		if a.Instances == 1 {
+			singleInstance = append(singleInstance, a.Name)
+		}
+	}
+
+	note := func(asgs []string, text string) {
+		if len(asgs) == 0 {
+			return
+		}
+		names := strings.Join(asgs, ", ")
+		if len(asgs) > reportRiskASGs {
+			names = fmt.Sprintf("%s and %d more", strings.Join(asgs[:reportRiskASGs], ", "), len(asgs)-reportRiskASGs)
+		}
+		ret = append(ret, fmt.Sprintf("%d AutoScaling groups %s: %s.", len(asgs), text, names))
+	}
+	note(fullSpot, "would run entirely on Spot, without any OnDemand capacity")
+	note(singleType, "use a single instance type, which increases the chance of Spot interruptions")
+	note(singleInstance, "have a single instance, so a Spot interruption leaves them without capacity until replaced")
+	note(unpriced, "have no pricing data, so their costs and savings are counted as zero in the totals")
+	note(nodeGroups, "are EKS managed node groups, which should be converted through the node group capacity type")
+
+	return ret
+}
+
+func (e *Estimate) assumptionRows() [][]string {
+	overrides := map[string]int{}
+	var keys []string
+	enabled := 0
+	for _, a := range e.ASGs {
+		if !a.Enabled {
+			continue
+		}
+		enabled++
+		k := fmt.Sprintf("at least %d instances and %.0f%% of the capacity", a.OnDemandNumber, a.OnDemandPercentage)
+		if overrides[k] == 0 {
+			keys = append(keys, k)
+		}
+		overrides[k]++
+	}
+
+	var od []string
+	for _, k := range keys {
+		od = append(od, fmt.Sprintf("%s on %d AutoScaling groups", k, overrides[k]))
+	}
+	if len(od) == 0 {
+		od = []string{"none"}
+	}
+
+	return [][]string{
+		{"Fee model", e.Assumptions.FeeModel},
+		{"Pricing source", e.Metadata.PricingSource.String()},
+		{"Spot pricing", e.Assumptions.SpotPricing},
+		{"Horizon", fmt.Sprintf("%s, %g hours", e.Metadata.PricingInterval, e.Metadata.HorizonHours)},
+		{"Currency", e.currencyNote()},
+		{"Hours per month", fmt.Sprintf("%.0f, used for prorating the monthly fees", e.Assumptions.HoursPerMonth)},
+		{"Converted to Spot", fmt.Sprintf("%d of %d AutoScaling groups", enabled, len(e.ASGs))},
+		{"OnDemand overrides", strings.Join(od, "; ")},
+	}
+}
+
+// writeHTMLReport writes a self-contained HTML report, with inline styles and
+// charts, which can be viewed without the application.
+func (e *Estimate) writeHTMLReport(w io.Writer) error {
+	return reportTemplate.Execute(w, e.report())
+}
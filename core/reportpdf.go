@@ -0,0 +1,172 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfMargin    = 15.0
+	pdfLine      = 6.0
+	pdfChartBar  = 3.5
+	pdfChartName = 55.0
+)
+
+// writePDFReport writes the same content as the HTML report as a PDF
+// document, for sharing it where HTML files aren't practical.
+func (e *Estimate) writePDFReport(w io.Writer) error {
+	r := e.report()
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	width := pageWidth - 2*pdfMargin
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin + 5)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, tr(fmt.Sprintf("Generated by the LeanerCloud Savings Estimator - %s - page %d", r.Currency, pdf.PageNo())),
+			"", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	heading := func(text string) {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 9, tr(text), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "", 10)
+	}
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(0, 10, "Savings estimate", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	account := e.Metadata.AccountID
+	if e.Metadata.AccountAlias != "" {
+		account += " (" + e.Metadata.AccountAlias + ")"
+	}
+	pdf.CellFormat(0, pdfLine, tr(fmt.Sprintf("Account %s, %s, generated at %s", account,
+		strings.Join(e.Metadata.Regions, ", "), e.Metadata.GeneratedAt.Format("2006-01-02 15:04 MST"))),
+		"", 1, "L", false, 0, "")
+
+	heading("Summary")
+	kpiWidth := width / float64(len(r.KPIs))
+	x, y := pdf.GetXY()
+	for i, kpi := range r.KPIs {
+		pdf.Rect(x+float64(i)*kpiWidth, y, kpiWidth-2, 18, "D")
+		pdf.SetXY(x+float64(i)*kpiWidth+2, y+2)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.CellFormat(kpiWidth-6, 7, tr(kpi.Value), "", 2, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(kpiWidth-6, 5, tr(kpi.Label), "", 0, "L", false, 0, "")
+	}
+	pdf.SetXY(x, y+20)
+
+	heading("Current versus projected costs")
+	if len(r.Bars) == 0 {
+		pdf.CellFormat(0, pdfLine, "No AutoScaling groups.", "", 1, "L", false, 0, "")
+	}
+	barArea := width - pdfChartName - 20
+	pdf.SetFont("Helvetica", "", 8)
+	for _, bar := range r.Bars {
+		x, y := pdf.GetXY()
+		if y+2*pdfChartBar > pageHeight-pdfMargin-10 {
+			pdf.AddPage()
+			x, y = pdf.GetXY()
+		}
+		scale := barArea / float64(reportChartWidth-reportChartLabels-80)
+
+		pdf.CellFormat(pdfChartName-2, 2*pdfChartBar, tr(bar.Name), "", 0, "R", false, 0, "")
+		pdf.SetFillColor(217, 83, 79)
+		pdf.Rect(x+pdfChartName, y, bar.CostsWidth*scale, pdfChartBar, "F")
+		pdf.SetFillColor(92, 184, 92)
+		pdf.Rect(x+pdfChartName, y+pdfChartBar, bar.ProjectedWidth*scale, pdfChartBar, "F")
+
+		pdf.SetXY(x+pdfChartName+bar.CostsWidth*scale+1, y)
+		pdf.CellFormat(20, pdfChartBar, fmt.Sprintf("%.2f", bar.Costs), "", 0, "L", false, 0, "")
+		pdf.SetXY(x+pdfChartName+bar.ProjectedWidth*scale+1, y+pdfChartBar)
+		pdf.CellFormat(20, pdfChartBar, fmt.Sprintf("%.2f", bar.ProjectedCosts), "", 0, "L", false, 0, "")
+		pdf.SetXY(x, y+2*pdfChartBar+1.5)
+	}
+	pdf.SetFont("Helvetica", "", 10)
+	if len(r.Bars) > 0 {
+		pdf.CellFormat(0, pdfLine, fmt.Sprintf("Largest AutoScaling groups by %s costs, current in red and projected in green.",
+			e.Metadata.PricingInterval), "", 1, "L", false, 0, "")
+	}
+
+	heading(fmt.Sprintf("Top %d saving opportunities", len(r.Top)))
+	if len(r.Top) == 0 {
+		pdf.CellFormat(0, pdfLine, "None of the AutoScaling groups would save costs with the current configuration.",
+			"", 1, "L", false, 0, "")
+	} else {
+		columns := []float64{60, 40, 18, 22, 22, 18}
+		columns = append(columns, width-sum(columns))
+		header := []string{"AutoScaling group", "Instance types", "Instances", "Current", "Projected", "Savings", "Savings %"}
+
+		pdf.SetFont("Helvetica", "B", 9)
+		for i, h := range header {
+			pdf.CellFormat(columns[i], pdfLine, h, "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+		for _, a := range r.Top {
+			cells := []string{
+				truncate(a.Name, 34), truncate(strings.Join(a.InstanceTypes, ", "), 22), fmt.Sprintf("%d", a.Instances),
+				fmt.Sprintf("%.2f", a.Costs), fmt.Sprintf("%.2f", a.ProjectedCosts),
+				fmt.Sprintf("%.2f", a.Savings), fmt.Sprintf("%.1f", a.SavingsPercent),
+			}
+			for i, cell := range cells {
+				align := "R"
+				if i < 2 {
+					align = "L"
+				}
+				pdf.CellFormat(columns[i], pdfLine, tr(cell), "1", 0, align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, pdfLine, fmt.Sprintf("Costs and savings are %s, in %s.", e.Metadata.PricingInterval, e.Metadata.Currency), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+	}
+
+	heading("Risk notes")
+	if len(r.Risks) == 0 {
+		pdf.CellFormat(0, pdfLine, "No risks identified.", "", 1, "L", false, 0, "")
+	}
+	for _, risk := range r.Risks {
+		pdf.MultiCell(0, 5, tr("- "+risk), "", "L", false)
+	}
+
+	keyValues := func(rows [][]string) {
+		for _, row := range rows {
+			pdf.SetFont("Helvetica", "B", 10)
+			pdf.CellFormat(45, 5, tr(row[0]), "", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.MultiCell(0, 5, tr(row[1]), "", "L", false)
+		}
+	}
+
+	heading("Assumptions")
+	keyValues(r.Assumptions)
+
+	heading("Details")
+	keyValues(r.Details)
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+func sum(values []float64) float64 {
+	var ret float64
+	for _, v := range values {
+		ret += v
+	}
+	return ret
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+const (
+	throttleMaxAttempts = 5
+	throttleBaseDelay   = 500 * time.Millisecond
+)
+
+// retryThrottled calls f until it succeeds, returns a non-throttling error
+// or runs out of attempts, backing off exponentially with jitter between
+// throttled attempts. This is on top of the SDK retries, which give up
+// quickly when changing hundreds of ASGs in a row.
+func retryThrottled(f func() error) error {
+	throttles := retry.IsErrorThrottles(retry.DefaultThrottles)
+
+	var err error
+	for attempt := 0; attempt < throttleMaxAttempts; attempt++ {
+		err = f()
+		if err == nil || throttles.IsErrorThrottle(err) != aws.TrueTernary {
+			return err
+		}
+
+		delay := throttleBaseDelay << attempt
+		delay += time.Duration(rand.Int63n(int64(delay / 2)))
+		log.Printf("Throttled by the AWS API, retrying in %v: %v", delay, err)
+		time.Sleep(delay)
+	}
+	return err
+}
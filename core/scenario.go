@@ -0,0 +1,282 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const scenarioVersion = 1
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// ScenarioASG holds the overrides of an ASG, as edited in the ASG table.
+type ScenarioASG struct {
+	Name               string  `json:"name"`
+	Selected           bool    `json:"selected"`
+	Enabled            bool    `json:"enabled"`
+	OnDemandNumber     int64   `json:"on_demand_number"`
+	OnDemandPercentage float64 `json:"on_demand_percentage"`
+}
+
+// ScenarioSettings holds the global settings of a scenario, which affect its
+// totals.
+type ScenarioSettings struct {
+	Horizon             Horizon       `json:"horizon"`
+	Filter              string        `json:"filter"`
+	Sort                ASGSort       `json:"sort"`
+	Fee                 FeeConfig     `json:"fee"`
+	Currency            string        `json:"currency"`
+	Discounts           DiscountRules `json:"discounts"`
+	FargateSpotDiscount float64       `json:"fargate_spot_discount"`
+}
+
+// Scenario is a named what-if configuration of the ASGs from a region,
+// persisted locally so it can be loaded back later.
+type Scenario struct {
+	Version   int              `json:"version"`
+	Name      string           `json:"name"`
+	Timestamp time.Time        `json:"timestamp"`
+	AccountID string           `json:"account_id"`
+	Region    string           `json:"region"`
+	Settings  ScenarioSettings `json:"settings"`
+	ASGs      []ScenarioASG    `json:"asgs"`
+
+	Path string `json:"-"`
+}
+
+func (s *Scenario) String() string {
+	return fmt.Sprintf("%s (%s, %d AutoScaling groups)", s.Name, s.Timestamp.Local().Format("2006-01-02 15:04"), len(s.ASGs))
+}
+
+// ScenarioDiff describes how a scenario matched the currently loaded ASGs.
+type ScenarioDiff struct {
+	Matched int
+	// Missing ASGs were saved in the scenario but no longer exist.
+	Missing []string
+	// New ASGs didn't exist when the scenario was saved, and kept their
+	// current configuration.
+	New []string
+}
+
+// scenario captures the current configuration of the ASGs from the current
+// region and the global settings.
+func (c *Launcher) scenario(name string) (*Scenario, error) {
+	if c.Identity == nil {
+		return nil, errors.New("missing credentials")
+	}
+
+	asgs := c.currentASGs()
+	if len(asgs) == 0 {
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+
+	s := Scenario{
+		Version:   scenarioVersion,
+		Name:      name,
+		Timestamp: time.Now().UTC(),
+		AccountID: c.Identity.AccountID,
+		Region:    c.CurrentRegion,
+		Settings: ScenarioSettings{
+			Horizon:             c.Horizon,
+			Sort:                c.ASGSort,
+			Fee:                 c.Fee,
+			Currency:            c.CurrencyCode(),
+			Discounts:           c.Discounts,
+			FargateSpotDiscount: c.FargateSpotDiscount,
+		},
+	}
+	if c.ASGFilter != nil {
+		s.Settings.Filter = c.ASGFilter.Expression
+	}
+
+	for _, asg := range asgs {
+		s.ASGs = append(s.ASGs, ScenarioASG{
+			Name:               *asg.AutoScalingGroupName,
+			Selected:           asg.Selected,
+			Enabled:            asg.Enabled,
+			OnDemandNumber:     asg.OnDemandNumber,
+			OnDemandPercentage: asg.OnDemandPercentage,
+		})
+	}
+	return &s, nil
+}
+
+// SaveScenario saves the current configuration under the given name,
+// replacing any previous scenario with the same name for the current account
+// and region.
+func (c *Launcher) SaveScenario(name string) (*Scenario, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("the scenario name can't be empty")
+	}
+
+	s, err := c.scenario(name)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := dataDir("scenarios")
+	if err != nil {
+		return nil, err
+	}
+
+	// The hash keeps names that only differ in unsafe characters, such as
+	// "a b" and "a/b", from sharing the same file.
+	hash := sha256.Sum256([]byte(name))
+	s.Path = filepath.Join(dir, fmt.Sprintf("%s-%s-%s-%x.json",
+		s.AccountID, s.Region, unsafeFileNameChars.ReplaceAllString(name, "_"), hash[:4]))
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(s.Path, data, 0600); err != nil {
+		return nil, err
+	}
+
+	log.Println("Saved scenario", name, "to", s.Path)
+	return s, nil
+}
+
+// ListScenarios returns the scenarios saved for the current account and
+// region, sorted by name.
+func (c *Launcher) ListScenarios() ([]*Scenario, error) {
+	if c.Identity == nil {
+		return nil, errors.New("missing credentials")
+	}
+
+	dir, err := dataDir("scenarios")
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*Scenario
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			log.Printf("Couldn't read scenario %s: %v", f, err)
+			continue
+		}
+
+		var s Scenario
+		if err := json.Unmarshal(data, &s); err != nil {
+			log.Printf("Couldn't parse scenario %s: %v", f, err)
+			continue
+		}
+
+		if s.Version > scenarioVersion {
+			log.Printf("Skipping scenario %s, saved by a newer version", f)
+			continue
+		}
+
+		if s.AccountID != c.Identity.AccountID || s.Region != c.CurrentRegion {
+			continue
+		}
+		s.Path = f
+		ret = append(ret, &s)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return strings.ToLower(ret[i].Name) < strings.ToLower(ret[j].Name)
+	})
+	return ret, nil
+}
+
+// LoadScenario applies the scenario to the ASGs of the current region, matching
+// them by name, and restores its global settings.
+func (c *Launcher) LoadScenario(s *Scenario) (*ScenarioDiff, error) {
+	if c.Identity == nil {
+		return nil, errors.New("missing credentials")
+	}
+
+	if s.AccountID != c.Identity.AccountID || s.Region != c.CurrentRegion {
+		return nil, fmt.Errorf("the scenario was saved for account %s in %s, but the current account is %s in %s",
+			s.AccountID, s.Region, c.Identity.AccountID, c.CurrentRegion)
+	}
+
+	asgs := c.currentASGs()
+	if len(asgs) == 0 {
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+
+	filter, err := ParseASGFilter(s.Settings.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter in the scenario: %w", err)
+	}
+
+	// All the settings are validated before changing anything.
+	if err := s.Settings.Horizon.validate(); err != nil {
+		return nil, fmt.Errorf("invalid horizon in the scenario: %w", err)
+	}
+	if _, err := s.Settings.Fee.Model(); err != nil {
+		return nil, fmt.Errorf("invalid fee model in the scenario: %w", err)
+	}
+	if _, ok := c.ExchangeRates.Rates[s.Settings.Currency]; !ok && s.Settings.Currency != BaseCurrency {
+		return nil, fmt.Errorf("no exchange rate for the currency %s of the scenario", s.Settings.Currency)
+	}
+	if err := s.Settings.Discounts.validate(); err != nil {
+		return nil, fmt.Errorf("invalid discounts in the scenario: %w", err)
+	}
+	if s.Settings.FargateSpotDiscount < 0 || s.Settings.FargateSpotDiscount > MaxFargateSpotDiscount {
+		return nil, fmt.Errorf("invalid FARGATE_SPOT discount in the scenario: %g%%", s.Settings.FargateSpotDiscount)
+	}
+
+	// The discounts reprice the ASGs, so they're applied before the overrides.
+	c.SetDiscounts(s.Settings.Discounts)
+
+	saved := map[string]ScenarioASG{}
+	for _, a := range s.ASGs {
+		saved[a.Name] = a
+	}
+
+	var diff ScenarioDiff
+	for _, asg := range asgs {
+		name := *asg.AutoScalingGroupName
+		a, ok := saved[name]
+		if !ok {
+			diff.New = append(diff.New, name)
+			continue
+		}
+		delete(saved, name)
+		diff.Matched++
+
+		asg.Selected = a.Selected
+		asg.Enabled = a.Enabled
+		asg.OnDemandNumber = a.OnDemandNumber
+		asg.OnDemandPercentage = a.OnDemandPercentage
+		if err := asg.CalculateHourlyPricing(); err != nil {
+			log.Printf("Couldn't recalculate the pricing of ASG %s: %v", name, err)
+		}
+	}
+
+	for name := range saved {
+		diff.Missing = append(diff.Missing, name)
+	}
+	sort.Strings(diff.Missing)
+
+	c.SetHorizon(s.Settings.Horizon)
+	c.ASGFilter = filter
+	c.ASGSort = s.Settings.Sort
+	c.SetFeeModel(s.Settings.Fee)
+	c.SetCurrency(s.Settings.Currency)
+	c.SetFargateSpotDiscount(s.Settings.FargateSpotDiscount)
+
+	log.Printf("Loaded scenario %s: %d matched, %d missing and %d new AutoScaling groups",
+		s.Name, diff.Matched, len(diff.Missing), len(diff.New))
+	return &diff, nil
+}
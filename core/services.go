@@ -5,6 +5,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
@@ -14,11 +16,13 @@ type services struct {
 	config      aws.Config
 	autoscaling *autoscaling.Client
 	ec2         *ec2.Client
+	ecs         *ecs.Client
 }
 
 type globalServices struct {
 	config         aws.Config
 	cloudformation *cloudformation.Client
+	iam            *iam.Client
 	s3             *s3.Client
 }
 
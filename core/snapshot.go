@@ -0,0 +1,312 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+const tagSnapshotVersion = 1
+
+var autoSpottingTags = []string{tagSpotEnabled, tagMinOnDemandNumber, tagMinOnDemandPercent}
+
+// ASGTagSnapshot holds the AutoSpotting tags of an ASG as they were before
+// applying a configuration. Tags missing from the map didn't exist.
+type ASGTagSnapshot struct {
+	Name string            `json:"name"`
+	Tags map[string]string `json:"tags"`
+}
+
+// TagSnapshot is the state of the AutoSpotting tags of the ASGs from a region,
+// persisted on disk before changing them so they can be rolled back.
+type TagSnapshot struct {
+	Version   int              `json:"version"`
+	Timestamp time.Time        `json:"timestamp"`
+	AccountID string           `json:"account_id"`
+	Region    string           `json:"region"`
+	ASGs      []ASGTagSnapshot `json:"asgs"`
+
+	Path string `json:"-"`
+}
+
+func (s *TagSnapshot) String() string {
+	return fmt.Sprintf("%s (%d AutoScaling groups)", s.Timestamp.Local().Format("2006-01-02 15:04:05"), len(s.ASGs))
+}
+
+// dataDir returns the directory used for storing the application data,
+// creating it if needed.
+func dataDir(elem ...string) (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(append([]string{base, "savings-estimator"}, elem...)...)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func (c *Launcher) snapshotAutoSpottingTags(plan *TagPlan) (*TagSnapshot, error) {
+	if c.Identity == nil || c.Regions == nil || c.Regions[plan.Region] == nil || c.Regions[plan.Region].AutoSpotting == nil {
+		return nil, errors.New("missing credentials")
+	}
+
+	snapshot := TagSnapshot{
+		Version:   tagSnapshotVersion,
+		Timestamp: time.Now().UTC(),
+		AccountID: c.Identity.AccountID,
+		Region:    plan.Region,
+	}
+
+	// Only the ASGs that are about to change are saved, with their tags read
+	// again from AWS in case they changed since they were loaded.
+	var names []string
+	for _, asgPlan := range plan.ASGs {
+		if len(asgPlan.Pending()) > 0 {
+			names = append(names, *asgPlan.ASG.AutoScalingGroupName)
+		}
+	}
+
+	current, err := c.Regions[plan.Region].AutoSpotting.describeASGs(names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		asg, ok := current[name]
+		if !ok {
+			log.Printf("AutoScaling group %s no longer exists, leaving it out of the snapshot", name)
+			continue
+		}
+		s := ASGTagSnapshot{
+			Name: name,
+			Tags: map[string]string{},
+		}
+		live := ASG{AutoScalingGroup: asg}
+		for _, key := range autoSpottingTags {
+			if value, ok := live.tagValue(key); ok {
+				s.Tags[key] = value
+			}
+		}
+		snapshot.ASGs = append(snapshot.ASGs, s)
+	}
+
+	if len(snapshot.ASGs) == 0 {
+		return &snapshot, nil
+	}
+
+	dir, err := dataDir("snapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Path, err = writeSnapshot(dir, snapshot.Timestamp, snapshot.AccountID, snapshot.Region, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Saved the AutoSpotting tags snapshot to", snapshot.Path)
+	return &snapshot, nil
+}
+
+// writeSnapshot saves the snapshot as a new JSON file of the directory, named
+// after its timestamp, account and region, and returns the file name.
+// Existing snapshots are never overwritten.
+func writeSnapshot(dir string, timestamp time.Time, accountID, region string, snapshot interface{}) (string, error) {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s-%s.json",
+		timestamp.Format("20060102T150405.000Z"), accountID, region))
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// describeASGsBatchSize is the maximum number of names accepted by
+// DescribeAutoScalingGroups.
+const describeASGsBatchSize = 50
+
+// describeASGs reads the current state of the named ASGs from AWS. Groups that
+// no longer exist are missing from the result.
+func (a *AutoSpotting) describeASGs(names []string) (map[string]types.AutoScalingGroup, error) {
+	ret := map[string]types.AutoScalingGroup{}
+	for start := 0; start < len(names); start += describeASGsBatchSize {
+		end := start + describeASGsBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+
+		paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(a.services.autoscaling,
+			&autoscaling.DescribeAutoScalingGroupsInput{AutoScalingGroupNames: names[start:end]})
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(context.TODO())
+			if err != nil {
+				return nil, err
+			}
+			for _, asg := range output.AutoScalingGroups {
+				ret[aws.ToString(asg.AutoScalingGroupName)] = asg
+			}
+		}
+	}
+	return ret, nil
+}
+
+// ListTagSnapshots returns the tag snapshots of the current account and
+// region, newest first.
+func (c *Launcher) ListTagSnapshots() ([]*TagSnapshot, error) {
+	if c.Identity == nil {
+		return nil, errors.New("missing credentials")
+	}
+
+	dir, err := dataDir("snapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*TagSnapshot
+	for _, f := range files {
+		if !strings.HasSuffix(f, fmt.Sprintf("-%s-%s.json", c.Identity.AccountID, c.CurrentRegion)) {
+			continue
+		}
+
+		data, err := os.ReadFile(f)
+		if err != nil {
+			log.Printf("Couldn't read snapshot %s: %v", f, err)
+			continue
+		}
+
+		var snapshot TagSnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			log.Printf("Couldn't parse snapshot %s: %v", f, err)
+			continue
+		}
+		snapshot.Path = f
+		ret = append(ret, &snapshot)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Timestamp.After(ret[j].Timestamp)
+	})
+	return ret, nil
+}
+
+// RollbackAutoSpottingTags restores the AutoSpotting tags of the selected ASGs
+// from the snapshot, deleting the tags that didn't exist when the snapshot was
+// taken.
+func (c *Launcher) RollbackAutoSpottingTags(snapshot *TagSnapshot) error {
+	if c.Identity == nil || c.Regions == nil || c.Regions[snapshot.Region] == nil {
+		return errors.New("missing credentials")
+	}
+
+	if snapshot.AccountID != c.Identity.AccountID {
+		return fmt.Errorf("the snapshot was taken in account %s, but we're connected to %s",
+			snapshot.AccountID, c.Identity.AccountID)
+	}
+
+	as := c.Regions[snapshot.Region].AutoSpotting
+	selected := map[string]*ASG{}
+	for _, asg := range as.ASGs {
+		if asg.Selected {
+			selected[*asg.AutoScalingGroupName] = asg
+		}
+	}
+
+	var failed []string
+	for _, s := range snapshot.ASGs {
+		asg, ok := selected[s.Name]
+		if !ok {
+			log.Printf("Skipping the rollback of ASG %s, which isn't selected", s.Name)
+			continue
+		}
+
+		log.Printf("Rolling back the tags of ASG %s", s.Name)
+
+		var restore, remove []types.Tag
+		for _, key := range autoSpottingTags {
+			tag := types.Tag{
+				Key:               aws.String(key),
+				ResourceId:        aws.String(s.Name),
+				ResourceType:      aws.String(asgResourceType),
+				PropagateAtLaunch: aws.Bool(false),
+			}
+			if value, ok := s.Tags[key]; ok {
+				tag.Value = aws.String(value)
+				restore = append(restore, tag)
+			} else {
+				remove = append(remove, tag)
+			}
+		}
+
+		if err := as.restoreTags(restore, remove); err != nil {
+			log.Printf("Could not roll back the tags of AutoScalingGroup %s, error: %s", s.Name, err.Error())
+			failed = append(failed, s.Name)
+			continue
+		}
+
+		for _, key := range autoSpottingTags {
+			if value, ok := s.Tags[key]; ok {
+				asg.setTagValue(key, value)
+			} else {
+				asg.deleteTagValue(key)
+			}
+		}
+		asg.readTags()
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("couldn't roll back the tags of %d AutoScaling groups: %s",
+			len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (a *AutoSpotting) restoreTags(restore, remove []types.Tag) error {
+	if len(restore) > 0 {
+		if err := retryThrottled(func() error {
+			_, err := a.services.autoscaling.CreateOrUpdateTags(context.TODO(), &autoscaling.CreateOrUpdateTagsInput{
+				Tags: restore,
+			})
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(remove) > 0 {
+		return retryThrottled(func() error {
+			_, err := a.services.autoscaling.DeleteTags(context.TODO(), &autoscaling.DeleteTagsInput{
+				Tags: remove,
+			})
+			return err
+		})
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+const (
+	tagSpotEnabled        = "spot-enabled"
+	tagMinOnDemandNumber  = "autospotting_min_on_demand_number"
+	tagMinOnDemandPercent = "autospotting_min_on_demand_percentage"
+	asgResourceType       = "auto-scaling-group"
+)
+
+type TagAction string
+
+const (
+	TagActionAdd       TagAction = "add"
+	TagActionChange    TagAction = "change"
+	TagActionUnchanged TagAction = "unchanged"
+)
+
+// TagChange describes what happens to an AutoSpotting tag of an ASG when
+// applying the configuration.
+type TagChange struct {
+	Key      string
+	OldValue string
+	NewValue string
+	Action   TagAction
+}
+
+type ASGTagPlan struct {
+	ASG     *ASG
+	Changes []TagChange
+}
+
+// Pending returns the tags that need to be created or updated.
+func (p ASGTagPlan) Pending() []TagChange {
+	var ret []TagChange
+	for _, change := range p.Changes {
+		if change.Action != TagActionUnchanged {
+			ret = append(ret, change)
+		}
+	}
+	return ret
+}
+
+// TagPlan is the dry-run of applying the AutoSpotting configuration tags to
+// the ASGs of a region.
+type TagPlan struct {
+	Region string
+	ASGs   []ASGTagPlan
+}
+
+// Count returns the number of tags for each action in the plan.
+func (p *TagPlan) Count() map[TagAction]int {
+	ret := map[TagAction]int{}
+	for _, asgPlan := range p.ASGs {
+		for _, change := range asgPlan.Changes {
+			ret[change.Action]++
+		}
+	}
+	return ret
+}
+
+func (asg *ASG) tagValue(key string) (string, bool) {
+	for _, tag := range asg.Tags {
+		if aws.ToString(tag.Key) == key {
+			return aws.ToString(tag.Value), true
+		}
+	}
+	return "", false
+}
+
+func (asg *ASG) setTagValue(key, value string) {
+	for i, tag := range asg.Tags {
+		if aws.ToString(tag.Key) == key {
+			asg.Tags[i].Value = aws.String(value)
+			return
+		}
+	}
+	asg.Tags = append(asg.Tags, types.TagDescription{
+		Key:               aws.String(key),
+		Value:             aws.String(value),
+		ResourceId:        asg.AutoScalingGroupName,
+		ResourceType:      aws.String(asgResourceType),
+		PropagateAtLaunch: aws.Bool(false),
+	})
+}
+
+func (asg *ASG) deleteTagValue(key string) {
+	for i, tag := range asg.Tags {
+		if aws.ToString(tag.Key) == key {
+			asg.Tags = append(asg.Tags[:i], asg.Tags[i+1:]...)
+			return
+		}
+	}
+}
+
+// desiredTags returns the AutoSpotting tags matching the ASG configuration.
+// Tags are only created when they differ from the AutoSpotting defaults, but
+// existing tags are always updated.
+func (asg *ASG) desiredTags() []TagChange {
+	var ret []TagChange
+
+	desire := func(key, value string, needed bool) {
+		old, exists := asg.tagValue(key)
+		if !needed && !exists {
+			return
+		}
+
+		ret = append(ret, TagChange{
+			Key:      key,
+			OldValue: old,
+			NewValue: value,
+			Action:   tagAction(old, value, exists),
+		})
+	}
+
+	desire(tagSpotEnabled, fmt.Sprintf("%t", asg.Enabled), asg.Enabled)
+	desire(tagMinOnDemandNumber, fmt.Sprintf("%d", asg.OnDemandNumber), asg.OnDemandNumber > 0)
+	desire(tagMinOnDemandPercent, fmt.Sprintf("%.2f", float64(asg.OnDemandPercentage)), asg.OnDemandPercentage > 0)
+
+	return ret
+}
+
+// tagAction determines the action needed to get a tag from its old value to
+// the new one. Values are compared as numbers or booleans where possible,
+// so "20" and "20.00" are considered the same.
+func tagAction(old, new string, exists bool) TagAction {
+	if !exists {
+		return TagActionAdd
+	}
+	if old == new {
+		return TagActionUnchanged
+	}
+
+	if o, err := strconv.ParseFloat(old, 64); err == nil {
+		if n, err := strconv.ParseFloat(new, 64); err == nil && o == n {
+			return TagActionUnchanged
+		}
+	}
+	if o, err := strconv.ParseBool(old); err == nil {
+		if n, err := strconv.ParseBool(new); err == nil && o == n {
+			return TagActionUnchanged
+		}
+	}
+	return TagActionChange
+}
+
+// PlanAutoSpottingTags computes the AutoSpotting tags that would be created or
+// updated for each selected ASG of the current region, without changing
+// anything.
+func (c *Launcher) PlanAutoSpottingTags() (*TagPlan, error) {
+	if c.Regions == nil || c.CurrentRegion == "" || c.Regions[c.CurrentRegion] == nil || c.Regions[c.CurrentRegion].AutoSpotting == nil || len(c.Regions[c.CurrentRegion].AutoSpotting.ASGs) == 0 {
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+
+	selected := c.SelectedASGs()
+	if len(selected) == 0 {
+		return nil, errors.New("no AutoScaling groups selected")
+	}
+
+	plan := TagPlan{Region: c.CurrentRegion}
+	for _, asg := range selected {
+		plan.ASGs = append(plan.ASGs, ASGTagPlan{
+			ASG:     asg,
+			Changes: asg.desiredTags(),
+		})
+	}
+	return &plan, nil
+}
+
+type ApplyStatus string
+
+const (
+	ApplyStatusApplied ApplyStatus = "applied"
+	ApplyStatusSkipped ApplyStatus = "skipped"
+	ApplyStatusFailed  ApplyStatus = "failed"
+)
+
+// ApplyResult is the outcome of applying the tag plan of an ASG.
+type ApplyResult struct {
+	ASGTagPlan
+	Status ApplyStatus
+	Err    error
+}
+
+// Failed returns a plan for retrying only the ASGs that failed.
+func (p *TagPlan) Failed(results []ApplyResult) *TagPlan {
+	failed := TagPlan{Region: p.Region}
+	for _, r := range results {
+		if r.Status == ApplyStatusFailed {
+			failed.ASGs = append(failed.ASGs, r.ASGTagPlan)
+		}
+	}
+	return &failed
+}
+
+// ApplyAutoSpottingTags applies the tag plan, after saving a snapshot of the
+// previous AutoSpotting tags that can be used for rolling it back. It returns
+// the result for each ASG from the plan.
+func (c *Launcher) ApplyAutoSpottingTags(plan *TagPlan) ([]ApplyResult, error) {
+	log.Printf("Appling tags for all ASGs")
+	if plan == nil || c.Regions == nil || c.Regions[plan.Region] == nil || c.Regions[plan.Region].AutoSpotting == nil {
+		log.Printf("Appling tags for all ASGs failes nil checks")
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+
+	if _, err := c.snapshotAutoSpottingTags(plan); err != nil {
+		return nil, fmt.Errorf("couldn't save the snapshot of the current tags, nothing was changed: %w", err)
+	}
+
+	return c.applyAutoSpottingTags(plan), nil
+}
+
+// RetryAutoSpottingTags applies the tag plan of the ASGs that failed to apply
+// before. It doesn't take a new snapshot, since the failed ASGs are unchanged
+// and already covered by the snapshot of the first attempt.
+func (c *Launcher) RetryAutoSpottingTags(plan *TagPlan) ([]ApplyResult, error) {
+	if plan == nil || c.Regions == nil || c.Regions[plan.Region] == nil || c.Regions[plan.Region].AutoSpotting == nil {
+		return nil, errors.New("no AutoScaling groups loaded for the current region")
+	}
+	return c.applyAutoSpottingTags(plan), nil
+}
+
+func (c *Launcher) applyAutoSpottingTags(plan *TagPlan) []ApplyResult {
+	as := c.Regions[plan.Region].AutoSpotting
+	results := make([]ApplyResult, 0, len(plan.ASGs))
+	for _, asgPlan := range plan.ASGs {
+		results = append(results, as.applyTagPlan(asgPlan))
+	}
+	return results
+}
+
+func (a *AutoSpotting) applyTagPlan(asgPlan ASGTagPlan) ApplyResult {
+	asg := asgPlan.ASG
+	result := ApplyResult{ASGTagPlan: asgPlan}
+
+	pending := asgPlan.Pending()
+	if len(pending) == 0 {
+		log.Printf("No tag changes needed for ASG %s", *asg.AutoScalingGroupName)
+		result.Status = ApplyStatusSkipped
+		return result
+	}
+
+	log.Printf("Appling tags for ASG %s", *asg.AutoScalingGroupName)
+
+	var tags []types.Tag
+	for _, change := range pending {
+		tags = append(tags, types.Tag{
+			Key:               aws.String(change.Key),
+			Value:             aws.String(change.NewValue),
+			ResourceId:        asg.AutoScalingGroupName,
+			ResourceType:      aws.String(asgResourceType),
+			PropagateAtLaunch: aws.Bool(false),
+		})
+	}
+
+	err := retryThrottled(func() error {
+		_, err := a.services.autoscaling.CreateOrUpdateTags(context.TODO(), &autoscaling.CreateOrUpdateTagsInput{
+			Tags: tags,
+		})
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Could not create tags for AutoScalingGroup %s, error: %s", *asg.AutoScalingGroupName, err.Error())
+		result.Status = ApplyStatusFailed
+		result.Err = err
+		return result
+	}
+
+	for _, change := range pending {
+		asg.setTagValue(change.Key, change.NewValue)
+	}
+	result.Status = ApplyStatusApplied
+	return result
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// ASGSort defines the order of the ASGs displayed in the table. Key is the
+// DataKey of the column to sort by, an empty Key keeps the AWS API order.
+type ASGSort struct {
+	Key        string `json:"key"`
+	Descending bool   `json:"descending"`
+}
+
+// ProjectedSavingsPercent returns the projected savings as a percentage of
+// the current costs.
+func (asg *ASG) ProjectedSavingsPercent() float64 {
+	if asg.HourlyCosts <= 0 {
+		return 0
+	}
+	return asg.ProjectedSavings / asg.HourlyCosts * 100
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	}
+	return 1
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (s ASGSort) compare(a, b *ASG) int {
+	switch s.Key {
+	case "AutoScalingGroupName":
+		return strings.Compare(strings.ToLower(*a.AutoScalingGroupName), strings.ToLower(*b.AutoScalingGroupName))
+	case "EKSNodeGroup":
+		return strings.Compare(a.EKSCluster+"/"+a.EKSNodeGroup, b.EKSCluster+"/"+b.EKSNodeGroup)
+	case "InstanceTypes":
+		return strings.Compare(strings.Join(a.InstanceTypes, ","), strings.Join(b.InstanceTypes, ","))
+	case "DesiredCapacity":
+		return compareFloat(float64(*a.DesiredCapacity), float64(*b.DesiredCapacity))
+	case "HourlyCosts":
+		return compareFloat(a.HourlyCosts, b.HourlyCosts)
+	case "ProjectedCosts":
+		return compareFloat(a.ProjectedCosts, b.ProjectedCosts)
+	case "ProjectedSavings":
+		return compareFloat(a.ProjectedSavings, b.ProjectedSavings)
+	case "ProjectedSavingsPercent":
+		return compareFloat(a.ProjectedSavingsPercent(), b.ProjectedSavingsPercent())
+	case "OnDemandPercentage":
+		return compareFloat(a.OnDemandPercentage, b.OnDemandPercentage)
+	case "OnDemandNumber":
+		return compareFloat(float64(a.OnDemandNumber), float64(b.OnDemandNumber))
+	case "Enabled":
+		return compareBool(a.Enabled, b.Enabled)
+	case "Selected":
+		return compareBool(a.Selected, b.Selected)
+	}
+	return 0
+}
+
+func (s ASGSort) apply(asgs []*ASG) {
+	if s.Key == "" {
+		return
+	}
+	sort.SliceStable(asgs, func(i, j int) bool {
+		if s.Descending {
+			return s.compare(asgs[i], asgs[j]) > 0
+		}
+		return s.compare(asgs[i], asgs[j]) < 0
+	})
+}
+
+func (c *Launcher) currentASGs() []*ASG {
+	if c.Regions == nil || c.Regions[c.CurrentRegion] == nil || c.Regions[c.CurrentRegion].AutoSpotting == nil {
+		return nil
+	}
+	return c.Regions[c.CurrentRegion].AutoSpotting.ASGs
+}
+
+// VisibleASGs returns the ASGs of the current region matching the ASG filter,
+// in the order given by the ASG sort, optionally grouped by EKS cluster.
+func (c *Launcher) VisibleASGs() []*ASG {
+	var ret []*ASG
+	for _, asg := range c.currentASGs() {
+		if c.ASGFilter.Match(asg, c.CostMultiplier()) {
+			ret = append(ret, asg)
+		}
+	}
+	c.ASGSort.apply(ret)
+	if c.GroupByCluster {
+		groupByCluster(ret)
+	}
+	return ret
+}
+
+// SelectedASGs returns the selected ASGs of the current region, which are
+// the only ones changed when applying or rolling back a configuration.
+func (c *Launcher) SelectedASGs() []*ASG {
+	var ret []*ASG
+	for _, asg := range c.currentASGs() {
+		if asg.Selected {
+			ret = append(ret, asg)
+		}
+	}
+	return ret
+}
+
+// SelectAll selects all the ASGs of the current region.
+func (c *Launcher) SelectAll() {
+	for _, asg := range c.currentASGs() {
+		asg.Selected = true
+	}
+}
+
+// SelectVisible selects only the ASGs matching the ASG filter.
+func (c *Launcher) SelectVisible() {
+	for _, asg := range c.currentASGs() {
+		asg.Selected = false
+	}
+	for _, asg := range c.VisibleASGs() {
+		asg.Selected = true
+	}
+}
+
+// InvertSelection inverts the selection of the ASGs matching the ASG filter.
+func (c *Launcher) InvertSelection() {
+	for _, asg := range c.VisibleASGs() {
+		asg.Selected = !asg.Selected
+	}
+}
@@ -1,6 +1,8 @@
 // Packascreenshot provides various examples of Fyne API capabilities.
 package main
 
+//go:generate go run ./cmd/iam-policy -format cloudformation -o cloudformation/template.yaml
+
 import (
 	"log"
 
@@ -30,6 +32,7 @@ func main() {
 
 	c = &core.Launcher{
 		PricingIntervalMultiplier: 1,
+		FargateSpotDiscount:       core.DefaultFargateSpotDiscount,
 	}
 
 	c.AutoSpottingCurrentTotalMonthlyCosts = binding.NewString()
@@ -38,6 +41,8 @@ func main() {
 	c.AutoSpottingProjectedSpotSavingsPercent = binding.NewString()
 	c.AutoSpottingProjectedAutoSpottingCharges = binding.NewString()
 	c.AutoSpottingProjectedNetSavings = binding.NewString()
+	c.FeeLabel = binding.NewString()
+	c.AccountIdentity = binding.NewString()
 
 	c.AutoSpottingCurrentTotalMonthlyCosts.Set("0")
 	c.AutoSpottingProjectedMonthlyCosts.Set("0")
@@ -45,6 +50,7 @@ func main() {
 	c.AutoSpottingProjectedSpotSavingsPercent.Set("0%")
 	c.AutoSpottingProjectedAutoSpottingCharges.Set("0")
 	c.AutoSpottingProjectedNetSavings.Set("0")
+	c.AccountIdentity.Set("Not connected")
 
 	data, err := ec2instancesinfo.Data()
 	if err != nil {
@@ -86,8 +92,13 @@ func main() {
 		content.Refresh()
 	}
 
+	identity := widget.NewLabelWithData(c.AccountIdentity)
+	identity.TextStyle = fyne.TextStyle{Monospace: true}
+
 	screen := container.NewBorder(
-		container.NewVBox(title, widget.NewSeparator(), intro), nil, nil, nil, content)
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil, identity, title),
+			widget.NewSeparator(), intro), nil, nil, nil, content)
 	if fyne.CurrentDevice().IsMobile() {
 		w.SetContent(makeNav(setScreen, false))
 	} else {
@@ -0,0 +1,208 @@
+package screens
+
+import (
+	"fmt"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+type comparisonColumn struct {
+	header string
+	value  func(cmp *core.Comparison, name string) string
+}
+
+var comparisonMetrics = []struct {
+	label string
+	value func(t core.Totals) float64
+}{
+	{"Current costs", func(t core.Totals) float64 { return t.CurrentCosts }},
+	{"Projected costs", func(t core.Totals) float64 { return t.ProjectedCosts }},
+	{"Spot savings", func(t core.Totals) float64 { return t.SpotSavings }},
+	{"Spot savings %", func(t core.Totals) float64 { return t.SpotSavingsPercent }},
+	{"Fee", func(t core.Totals) float64 { return t.Fee }},
+	{"Net savings", func(t core.Totals) float64 { return t.NetSavings }},
+}
+
+// comparisonColumns builds the columns of the per-ASG table, with the
+// figures of each scenario next to each other, and the net savings delta
+// against the first scenario.
+func comparisonColumns(cmp *core.Comparison) []comparisonColumn {
+	columns := []comparisonColumn{{
+		header: "AutoScaling Group Name",
+		value:  func(_ *core.Comparison, name string) string { return name },
+	}}
+
+	for i, r := range cmp.Results {
+		i := i
+		cell := func(f func(core.ComparisonCell) float64) func(*core.Comparison, string) string {
+			return func(cmp *core.Comparison, name string) string {
+				return fmt.Sprintf("%.2f", f(cmp.Cell(i, name)))
+			}
+		}
+		columns = append(columns,
+			comparisonColumn{r.Name + ": projected " + cmp.Currency, cell(func(c core.ComparisonCell) float64 { return c.ProjectedCosts })},
+			comparisonColumn{r.Name + ": savings " + cmp.Currency, cell(func(c core.ComparisonCell) float64 { return c.Savings })},
+			comparisonColumn{r.Name + ": fee " + cmp.Currency, cell(func(c core.ComparisonCell) float64 { return c.Fee })},
+			comparisonColumn{r.Name + ": net savings " + cmp.Currency, cell(func(c core.ComparisonCell) float64 { return c.NetSavings })},
+		)
+		if i == 0 {
+			continue
+		}
+		columns = append(columns, comparisonColumn{
+			header: fmt.Sprintf("%s: net savings vs %s", r.Name, cmp.Results[0].Name),
+			value: func(cmp *core.Comparison, name string) string {
+				return fmt.Sprintf("%+.2f", cmp.Cell(i, name).NetSavings-cmp.Cell(0, name).NetSavings)
+			},
+		})
+	}
+	return columns
+}
+
+func compareScenarios(w fyne.Window, c *core.Launcher) *container.TabItem {
+	var cmp *core.Comparison
+	var columns []comparisonColumn
+
+	scenarios := map[string]*core.Scenario{}
+	choices := widget.NewCheckGroup([]string{core.CurrentScenarioName}, nil)
+	choices.Horizontal = true
+
+	reload := func() {
+		scenarios = map[string]*core.Scenario{}
+		options := []string{core.CurrentScenarioName}
+		saved, err := c.ListScenarios()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		for _, s := range saved {
+			scenarios[s.Name] = s
+			options = append(options, s.Name)
+		}
+		choices.Options = options
+		choices.Refresh()
+	}
+
+	totals := widget.NewTableWithHeaders(
+		func() (int, int) {
+			if cmp == nil {
+				return 0, 0
+			}
+			return len(comparisonMetrics) + 1, len(cmp.Results) + 1
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			if id.Row == 0 {
+				if id.Col == 0 {
+					label.SetText("Fee model")
+				} else {
+					label.SetText(cmp.Results[id.Col-1].FeeModel.Label())
+				}
+				return
+			}
+
+			metric := comparisonMetrics[id.Row-1]
+			if id.Col == 0 {
+				label.SetText(metric.label)
+				return
+			}
+			value := metric.value(cmp.Results[id.Col-1].Totals)
+			text := fmt.Sprintf("%.2f", value)
+			if id.Col > 1 {
+				text += fmt.Sprintf(" (%+.2f)", value-metric.value(cmp.Results[0].Totals))
+			}
+			label.SetText(text)
+		})
+	totals.ShowHeaderColumn = false
+	totals.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		switch {
+		case id.Col == 0 && cmp != nil:
+			header.SetText(fmt.Sprintf("Totals (%s, %s)", cmp.Horizon.Label(), cmp.Currency))
+		case id.Col == 0:
+			header.SetText("Totals")
+		case cmp != nil && id.Col > 0 && id.Col <= len(cmp.Results):
+			header.SetText(cmp.Results[id.Col-1].Name)
+		}
+	}
+
+	asgs := widget.NewTableWithHeaders(
+		func() (int, int) {
+			if cmp == nil {
+				return 0, 0
+			}
+			return len(cmp.ASGNames), len(columns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(columns[id.Col].value(cmp, cmp.ASGNames[id.Row]))
+		})
+	asgs.ShowHeaderColumn = false
+	asgs.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col >= 0 && id.Col < len(columns) {
+			header.SetText(columns[id.Col].header)
+		}
+	}
+
+	compare := widget.NewButton("Compare", func() {
+		var selected []*core.Scenario
+		for _, name := range choices.Selected {
+			if name == core.CurrentScenarioName {
+				s, err := c.CurrentScenario()
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				selected = append(selected, s)
+				continue
+			}
+			if s, ok := scenarios[name]; ok {
+				selected = append(selected, s)
+			}
+		}
+
+		result, err := c.CompareScenarios(selected)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		cmp, columns = result, comparisonColumns(result)
+
+		totals.SetColumnWidth(0, 200)
+		for i := range cmp.Results {
+			totals.SetColumnWidth(i+1, 280)
+		}
+		asgs.SetColumnWidth(0, 300)
+		for i := 1; i < len(columns); i++ {
+			asgs.SetColumnWidth(i, float32(30+7*len(columns[i].header)))
+		}
+		totals.Refresh()
+		asgs.Refresh()
+	})
+
+	totalsView := container.NewVScroll(totals)
+	totalsView.SetMinSize(fyne.NewSize(0, 300))
+
+	return container.NewTabItem("Compare scenarios", container.NewBorder(
+		container.NewVBox(
+			widget.NewLabel("Select two or more scenarios to compare them against the AutoScaling groups loaded "+
+				"for the current region. Deltas are relative to the first selected scenario."),
+			container.NewBorder(nil, nil, nil,
+				container.NewHBox(widget.NewButton("Reload scenarios", reload), compare),
+				choices),
+			totalsView,
+		),
+		nil, nil, nil, asgs))
+}
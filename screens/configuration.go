@@ -7,6 +7,7 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -17,7 +18,7 @@ const (
 	preferenceAutoSpottingVersion = "AutoSpottingVersion"
 )
 
-func staticAuth(a fyne.App, c *core.Launcher) *widget.AccordionItem {
+func staticAuth(a fyne.App, w fyne.Window, c *core.Launcher) *widget.AccordionItem {
 	currentPrefRegion := a.Preferences().StringWithFallback(preferenceRegion, "us-east-1")
 
 	accessKey := widget.NewEntry()
@@ -32,7 +33,9 @@ func staticAuth(a fyne.App, c *core.Launcher) *widget.AccordionItem {
 	regionsStaticAuth := widget.NewSelect(c.AWSRegions(), func(s string) {
 		a.Preferences().SetString(preferenceRegion, s)
 		log.Println("selected region", s)
-		c.ConnectWithStaticAuth(accessKey.Text, secret.Text, sessionToken.Text)
+		if err := c.ConnectWithStaticAuth(accessKey.Text, secret.Text, sessionToken.Text); err != nil {
+			dialog.ShowError(err, w)
+		}
 		c.SetRegion(s)
 	})
 
@@ -51,18 +54,37 @@ func staticAuth(a fyne.App, c *core.Launcher) *widget.AccordionItem {
 
 }
 
-func profileAuth(a fyne.App, c *core.Launcher) *widget.AccordionItem {
+func profileAuth(a fyne.App, w fyne.Window, c *core.Launcher) *widget.AccordionItem {
 	currentPrefRegion := a.Preferences().StringWithFallback(preferenceRegion, "us-east-1")
 
 	currentPrefProfile := a.Preferences().String(preferenceProfile)
 	regionsProfileAuth := widget.NewSelect(c.AWSRegions(), func(s string) {})
-	profiles := widget.NewSelect(c.ReadAWSProfiles(), func(s string) {
-		a.Preferences().SetString(preferenceProfile, s)
-		log.Println("selected profile", s)
-		c.ConnectWithProfileAuth(regionsProfileAuth.Selected)
-		c.SetRegion(s)
+
+	// The picker shows the credential type next to each profile name, so we
+	// need to map the displayed option back to the profile name.
+	profileNames := map[string]string{}
+	options := []string{}
+	currentPrefOption := ""
+	for _, p := range c.ReadAWSProfiles() {
+		profileNames[p.String()] = p.Name
+		options = append(options, p.String())
+		if p.Name == currentPrefProfile {
+			currentPrefOption = p.String()
+		}
+	}
+
+	profiles := widget.NewSelect(options, func(s string) {
+		profile := profileNames[s]
+		a.Preferences().SetString(preferenceProfile, profile)
+		log.Println("selected profile", profile)
+		if err := c.ConnectWithProfileAuth(profile); err != nil {
+			dialog.ShowError(err, w)
+		}
+		c.SetRegion(regionsProfileAuth.Selected)
 	})
-	profiles.SetSelected(currentPrefProfile)
+	if currentPrefOption != "" {
+		profiles.SetSelected(currentPrefOption)
+	}
 
 	regionsProfileAuth.SetSelected(currentPrefRegion)
 
@@ -71,7 +93,9 @@ func profileAuth(a fyne.App, c *core.Launcher) *widget.AccordionItem {
 	regionsProfileAuth.OnChanged = func(s string) {
 		a.Preferences().SetString(preferenceRegion, s)
 		log.Println("selected region", s)
-		c.ConnectWithProfileAuth(profiles.Selected)
+		if err := c.ConnectWithProfileAuth(profileNames[profiles.Selected]); err != nil {
+			dialog.ShowError(err, w)
+		}
 		c.SetRegion(s)
 	}
 	return widget.NewAccordionItem("AWS Profile", container.NewVBox(
@@ -83,9 +107,9 @@ func profileAuth(a fyne.App, c *core.Launcher) *widget.AccordionItem {
 			}}))
 }
 
-func authentication(a fyne.App, c *core.Launcher) *container.TabItem {
+func authentication(a fyne.App, w fyne.Window, c *core.Launcher) *container.TabItem {
 
-	acc := widget.NewAccordion(staticAuth(a, c), profileAuth(a, c))
+	acc := widget.NewAccordion(staticAuth(a, w, c), profileAuth(a, w, c))
 	acc.MultiOpen = true
 	return container.NewTabItem("Authentication", acc)
 }
@@ -174,12 +198,14 @@ func ebsOptimizerConfiguration(a fyne.App, c *core.Launcher) *container.TabItem
 	return container.NewTabItem("EBS Optimizer", widget.NewLabel("ToDo"))
 }
 
-func configuration(_ fyne.Window, c *core.Launcher) fyne.CanvasObject {
+func configuration(w fyne.Window, c *core.Launcher) fyne.CanvasObject {
 
 	a := fyne.CurrentApp()
 
 	return container.NewAppTabs(
-		authentication(a, c),
+		authentication(a, w, c),
+		permissionsPreflight(w, c),
+		iamPolicyGenerator(w),
 		// autoSpottingConfiguration(a, c),
 		// ebsOptimizerConfiguration(a, c),
 	)
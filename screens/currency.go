@@ -0,0 +1,160 @@
+package screens
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+const preferenceCurrency = "Currency"
+
+// currencySettings holds the widgets used for selecting the currency, which
+// is persisted in the preferences, and for editing the exchange rates.
+type currencySettings struct {
+	w        fyne.Window
+	c        *core.Launcher
+	currency *widget.Select
+	syncing  bool
+	onChange func()
+}
+
+func newCurrencySettings(w fyne.Window, c *core.Launcher, onChange func()) *currencySettings {
+	s := &currencySettings{w: w, c: c, onChange: onChange}
+
+	rates, err := core.LoadExchangeRates()
+	if err != nil {
+		log.Println("Couldn't load the exchange rates, using the default ones:", err)
+	}
+	c.SetExchangeRates(rates)
+
+	code := fyne.CurrentApp().Preferences().StringWithFallback(preferenceCurrency, core.BaseCurrency)
+	if err := c.SetCurrency(code); err != nil {
+		log.Println(err)
+	}
+
+	s.currency = widget.NewSelect(nil, s.apply)
+	s.Sync()
+
+	return s
+}
+
+func (s *currencySettings) apply(code string) {
+	if s.syncing {
+		return
+	}
+	log.Println("selected currency", code)
+
+	if err := s.c.SetCurrency(code); err != nil {
+		dialog.ShowError(err, s.w)
+		return
+	}
+	fyne.CurrentApp().Preferences().SetString(preferenceCurrency, code)
+
+	if s.onChange != nil {
+		s.onChange()
+	}
+}
+
+// Sync updates the widgets from the exchange rates and the selected currency.
+func (s *currencySettings) Sync() {
+	s.syncing = true
+	defer func() { s.syncing = false }()
+
+	s.currency.Options = s.c.ExchangeRates.Currencies()
+	s.currency.SetSelected(s.c.CurrencyCode())
+}
+
+// showRatesDialog lets the user edit the exchange rates as CSV lines, or
+// import them from a JSON or CSV file.
+func (s *currencySettings) showRatesDialog() {
+	date := widget.NewEntry()
+	date.SetPlaceHolder("YYYY-MM-DD")
+	rates := widget.NewMultiLineEntry()
+	rates.SetPlaceHolder("EUR,0.92")
+	rates.SetMinRowsVisible(8)
+
+	fill := func(r core.ExchangeRates) {
+		date.SetText(r.Date)
+		var codes []string
+		for code := range r.Rates {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		var lines []string
+		for _, code := range codes {
+			lines = append(lines, fmt.Sprintf("%s,%g", code, r.Rates[code]))
+		}
+		rates.SetText(strings.Join(lines, "\n"))
+	}
+	fill(s.c.ExchangeRates)
+
+	importButton := widget.NewButton("Import from file", func() {
+		open := dialog.NewFileOpen(func(uc fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, s.w)
+				return
+			}
+			if uc == nil {
+				return
+			}
+			defer uc.Close()
+
+			r, err := core.ImportExchangeRates(uc)
+			if err != nil {
+				dialog.ShowError(err, s.w)
+				return
+			}
+			log.Println("Imported the exchange rates from", uc.URI())
+			fill(r)
+		}, s.w)
+		open.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".json"}))
+		open.Show()
+	})
+
+	form := container.NewBorder(
+		container.NewVBox(
+			widget.NewLabel("Amount of each currency for one USD, one currency per line."),
+			widget.NewForm(widget.NewFormItem("Date", date)),
+		),
+		importButton, nil, nil, rates)
+
+	d := dialog.NewCustomConfirm("Exchange rates", "Save", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		r, err := core.ImportExchangeRates(strings.NewReader("date," + date.Text + "\n" + rates.Text))
+		if err == nil {
+			err = core.SaveExchangeRates(r)
+		}
+		if err == nil {
+			err = s.c.SetExchangeRates(r)
+		}
+		if err != nil {
+			dialog.ShowError(err, s.w)
+			return
+		}
+
+		fyne.CurrentApp().Preferences().SetString(preferenceCurrency, s.c.CurrencyCode())
+		s.Sync()
+		if s.onChange != nil {
+			s.onChange()
+		}
+	}, s.w)
+	d.Resize(fyne.NewSize(400, 400))
+	d.Show()
+}
+
+func (s *currencySettings) content() fyne.CanvasObject {
+	return container.NewGridWithColumns(2, s.currency,
+		widget.NewButton("Exchange rates", s.showRatesDialog))
+}
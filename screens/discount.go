@@ -0,0 +1,191 @@
+package screens
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+var discountScopeNames = map[core.DiscountScope]string{
+	core.DiscountGlobal:       "Global",
+	core.DiscountRegion:       "Region",
+	core.DiscountFamily:       "Instance family",
+	core.DiscountInstanceType: "Instance type",
+}
+
+var discountMatchPlaceHolders = map[core.DiscountScope]string{
+	core.DiscountRegion:       "us-east-1",
+	core.DiscountFamily:       "m5",
+	core.DiscountInstanceType: "m5.large",
+}
+
+var discountTargetNames = map[core.DiscountTarget]string{
+	core.DiscountOnDemand: "OnDemand",
+	core.DiscountSpot:     "Spot",
+	core.DiscountBoth:     "OnDemand and Spot",
+}
+
+// discountRuleRow holds the widgets for editing a discount rule.
+type discountRuleRow struct {
+	scope     *widget.Select
+	match     *widget.Entry
+	appliesTo *widget.Select
+	percent   *widget.Entry
+}
+
+func newDiscountRuleRow(r core.DiscountRule, onDelete func()) (*discountRuleRow, fyne.CanvasObject) {
+	row := &discountRuleRow{
+		match:   widget.NewEntry(),
+		percent: widget.NewEntry(),
+	}
+
+	var scopes []string
+	for _, s := range core.DiscountScopes {
+		scopes = append(scopes, discountScopeNames[s])
+	}
+	row.scope = widget.NewSelect(scopes, func(s string) {
+		scope := row.scopeValue()
+		row.match.SetPlaceHolder(discountMatchPlaceHolders[scope])
+		if scope == core.DiscountGlobal {
+			row.match.SetText("")
+			row.match.Disable()
+			return
+		}
+		row.match.Enable()
+	})
+
+	var targets []string
+	for _, t := range core.DiscountTargets {
+		targets = append(targets, discountTargetNames[t])
+	}
+	row.appliesTo = widget.NewSelect(targets, nil)
+
+	row.percent.SetPlaceHolder("% off")
+	row.match.SetText(r.Match)
+	row.scope.SetSelected(discountScopeNames[r.Scope])
+	row.appliesTo.SetSelected(discountTargetNames[r.AppliesTo])
+	if r.Percent != 0 {
+		row.percent.SetText(fmt.Sprintf("%g", r.Percent))
+	}
+
+	remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), onDelete)
+	return row, container.NewBorder(nil, nil, nil, remove,
+		container.NewGridWithColumns(4, row.scope, row.match, row.appliesTo, row.percent))
+}
+
+func (row *discountRuleRow) scopeValue() core.DiscountScope {
+	for s, name := range discountScopeNames {
+		if name == row.scope.Selected {
+			return s
+		}
+	}
+	return ""
+}
+
+func (row *discountRuleRow) rule() (core.DiscountRule, error) {
+	r := core.DiscountRule{
+		Scope: row.scopeValue(),
+		Match: row.match.Text,
+	}
+	for t, name := range discountTargetNames {
+		if name == row.appliesTo.Selected {
+			r.AppliesTo = t
+		}
+	}
+
+	percent, err := strconv.ParseFloat(row.percent.Text, 64)
+	if err != nil {
+		return r, fmt.Errorf("invalid discount percentage %q", row.percent.Text)
+	}
+	r.Percent = percent
+	return r, nil
+}
+
+// showDiscountsDialog lets the user edit the discount rules applied to the
+// list prices, which are saved in the application data.
+func showDiscountsDialog(w fyne.Window, c *core.Launcher, onChange func()) {
+	var rows []*discountRuleRow
+	list := container.NewVBox()
+
+	var add func(core.DiscountRule)
+	add = func(r core.DiscountRule) {
+		var row *discountRuleRow
+		var content fyne.CanvasObject
+		row, content = newDiscountRuleRow(r, func() {
+			for i := range rows {
+				if rows[i] == row {
+					rows = append(rows[:i], rows[i+1:]...)
+					break
+				}
+			}
+			list.Remove(content)
+		})
+		rows = append(rows, row)
+		list.Add(content)
+	}
+
+	for _, r := range c.Discounts {
+		add(r)
+	}
+
+	addButton := widget.NewButtonWithIcon("Add rule", theme.ContentAddIcon(), func() {
+		add(core.DiscountRule{Scope: core.DiscountGlobal, AppliesTo: core.DiscountOnDemand})
+	})
+
+	help := widget.NewLabel("Discounts reduce the list prices, for example for an EDP discount or private pricing.\n" +
+		"Only the most specific matching rule applies to each of the OnDemand and Spot prices.")
+
+	content := container.NewBorder(help, addButton, nil, nil, container.NewVScroll(list))
+
+	d := dialog.NewCustomConfirm("Discounts", "Save", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		var rules core.DiscountRules
+		for _, row := range rows {
+			r, err := row.rule()
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			rules = append(rules, r)
+		}
+
+		err := core.SaveDiscountRules(rules)
+		if err == nil {
+			err = c.SetDiscounts(rules)
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		log.Printf("Applied %d discount rules", len(rules))
+
+		if onChange != nil {
+			onChange()
+		}
+	}, w)
+	d.Resize(fyne.NewSize(700, 400))
+	d.Show()
+}
+
+// loadDiscounts applies the discount rules saved in the application data.
+func loadDiscounts(c *core.Launcher) {
+	rules, err := core.LoadDiscountRules()
+	if err != nil {
+		log.Println("Couldn't load the discount rules:", err)
+		return
+	}
+	if err := c.SetDiscounts(rules); err != nil {
+		log.Println("Couldn't apply the discount rules:", err)
+	}
+}
@@ -0,0 +1,217 @@
+package screens
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var ecsCapacityProviderColumns = []struct {
+	header   string
+	currency bool
+	value    func(c *core.Launcher, p *core.ECSCapacityProvider) string
+}{
+	{"ECS cluster", false, func(_ *core.Launcher, p *core.ECSCapacityProvider) string { return p.Cluster }},
+	{"Capacity provider", false, func(_ *core.Launcher, p *core.ECSCapacityProvider) string { return p.Name }},
+	{"AutoScaling group", false, func(_ *core.Launcher, p *core.ECSCapacityProvider) string {
+		if p.ASG() == nil {
+			return p.ASGName + " (not loaded)"
+		}
+		return p.ASGName
+	}},
+	{"Cost", true, func(c *core.Launcher, p *core.ECSCapacityProvider) string {
+		asg := p.ASG()
+		if asg == nil {
+			return ""
+		}
+		return fmt.Sprintf("%.2f", asg.HourlyCosts*c.CostMultiplier())
+	}},
+	{"Projected Savings", true, func(c *core.Launcher, p *core.ECSCapacityProvider) string {
+		asg := p.ASG()
+		if asg == nil {
+			return ""
+		}
+		return fmt.Sprintf("%.2f", asg.ProjectedSavings*c.CostMultiplier())
+	}},
+}
+
+var ecsServiceColumns = []struct {
+	header   string
+	currency bool
+	value    func(c *core.Launcher, s *core.ECSService) string
+}{
+	{"ECS cluster", false, func(_ *core.Launcher, s *core.ECSService) string { return s.Cluster }},
+	{"Service", false, func(_ *core.Launcher, s *core.ECSService) string { return s.Name }},
+	{"Capacity", false, func(_ *core.Launcher, s *core.ECSService) string { return s.Capacity }},
+	{"Tasks", false, func(_ *core.Launcher, s *core.ECSService) string { return fmt.Sprintf("%d", s.DesiredCount) }},
+	{"Task size", false, func(_ *core.Launcher, s *core.ECSService) string {
+		if !s.IsFargate() {
+			return ""
+		}
+		return fmt.Sprintf("%g vCPU, %g GB", s.VCPU, s.MemoryGB)
+	}},
+	{"Platform", false, func(_ *core.Launcher, s *core.ECSService) string {
+		if !s.IsFargate() {
+			return ""
+		}
+		return fmt.Sprintf("%s/%s", s.OS, s.Architecture)
+	}},
+	{"Spot %", false, func(_ *core.Launcher, s *core.ECSService) string { return fmt.Sprintf("%.0f%%", s.SpotShare*100) }},
+	{"Cost", true, func(c *core.Launcher, s *core.ECSService) string {
+		return fmt.Sprintf("%.2f", s.HourlyCosts*c.CostMultiplier())
+	}},
+	{"Projected Cost", true, func(c *core.Launcher, s *core.ECSService) string {
+		return fmt.Sprintf("%.2f", s.ProjectedCosts*c.CostMultiplier())
+	}},
+	{"Projected Savings", true, func(c *core.Launcher, s *core.ECSService) string {
+		return fmt.Sprintf("%.2f", s.ProjectedSavings*c.CostMultiplier())
+	}},
+	{"Notes", false, func(_ *core.Launcher, s *core.ECSService) string { return s.Note }},
+}
+
+const preferenceFargateSpotDiscount = "FargateSpotDiscount"
+
+// ecsTab returns the ECS and Fargate tab, along with a function updating it
+// after the FARGATE_SPOT discount was changed elsewhere, such as by loading a
+// scenario.
+func ecsTab(w fyne.Window, c *core.Launcher) (*container.TabItem, func()) {
+	prefs := fyne.CurrentApp().Preferences()
+	var providers []*core.ECSCapacityProvider
+	var services []*core.ECSService
+
+	discount := widget.NewEntry()
+	discount.Validator = validation.NewRegexp(`^[0-9]+(\.[0-9]+)?$`, "Must be a percentage")
+	discount.SetText(prefs.StringWithFallback(preferenceFargateSpotDiscount,
+		strconv.FormatFloat(core.DefaultFargateSpotDiscount, 'g', -1, 64)))
+	if d, err := strconv.ParseFloat(discount.Text, 64); err == nil {
+		if err := c.SetFargateSpotDiscount(d); err != nil {
+			log.Println(err.Error())
+		}
+	}
+
+	providerTable := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(providers), len(ecsCapacityProviderColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(ecsCapacityProviderColumns[id.Col].value(c, providers[id.Row]))
+		})
+	providerTable.ShowHeaderColumn = false
+	providerTable.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col < 0 || id.Col >= len(ecsCapacityProviderColumns) {
+			return
+		}
+		text := ecsCapacityProviderColumns[id.Col].header
+		if ecsCapacityProviderColumns[id.Col].currency {
+			text += " " + c.CurrencyCode()
+		}
+		header.SetText(text)
+	}
+	for i, width := range []float32{200, 250, 300, 150, 180} {
+		providerTable.SetColumnWidth(i, width)
+	}
+
+	serviceTable := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(services), len(ecsServiceColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(ecsServiceColumns[id.Col].value(c, services[id.Row]))
+		})
+	serviceTable.ShowHeaderColumn = false
+	serviceTable.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col < 0 || id.Col >= len(ecsServiceColumns) {
+			return
+		}
+		text := ecsServiceColumns[id.Col].header
+		if ecsServiceColumns[id.Col].currency {
+			text += " " + c.CurrencyCode()
+		}
+		header.SetText(text)
+	}
+	for i, width := range []float32{200, 250, 200, 70, 150, 200, 80, 120, 150, 180, 350} {
+		serviceTable.SetColumnWidth(i, width)
+	}
+
+	summary := widget.NewLabel("Load the ECS clusters of the current region to see their capacity providers and services.")
+	summary.Wrapping = fyne.TextWrapWord
+
+	update := func() {
+		providers, services = c.ECSCapacityProviders(), c.ECSServices()
+		t := c.FargateTotals()
+		summary.SetText(fmt.Sprintf("Fargate totals (%s, %s): current costs %.2f, projected costs %.2f, FARGATE_SPOT savings up to %.2f, "+
+			"assuming a %g%% discount while AWS advertises FARGATE_SPOT as up to %g%% cheaper, so the actual savings may be lower. "+
+			"The Fargate prices bundled on %s are approximate, and FARGATE_SPOT has no AutoSpotting fee.",
+			c.PricingInterval(), c.CurrencyCode(), t.CurrentCosts, t.ProjectedCosts, t.SpotSavings,
+			c.FargateSpotDiscount, core.MaxFargateSpotDiscount, core.FargatePricingDate))
+		providerTable.Refresh()
+		serviceTable.Refresh()
+	}
+
+	refresh := func() {
+		if err := discount.Validate(); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		d, _ := strconv.ParseFloat(discount.Text, 64)
+		if err := c.SetFargateSpotDiscount(d); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		prefs.SetString(preferenceFargateSpotDiscount, discount.Text)
+		update()
+	}
+
+	load := widget.NewButton("Load ECS clusters", func() {
+		progress := dialog.NewCustomWithoutButtons("Loading ECS clusters",
+			widget.NewProgressBarInfinite(), w)
+		progress.Show()
+
+		go func() {
+			err := c.LoadECSData()
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			refresh()
+		}()
+	})
+
+	sync := func() {
+		discount.SetText(strconv.FormatFloat(c.FargateSpotDiscount, 'g', -1, 64))
+		if providers != nil || services != nil {
+			update()
+		}
+	}
+
+	return container.NewTabItem("ECS and Fargate", container.NewBorder(
+		container.NewVBox(
+			container.NewHBox(load, widget.NewButton("Refresh costs", refresh)),
+			widget.NewForm(widget.NewFormItem("FARGATE_SPOT discount %", discount)),
+			summary,
+		),
+		nil, nil, nil,
+		container.NewVSplit(
+			container.NewBorder(widget.NewLabel("ASG capacity providers"), nil, nil, nil, providerTable),
+			container.NewBorder(widget.NewLabel("Services"), nil, nil, nil, serviceTable),
+		))), sync
+}
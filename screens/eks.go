@@ -0,0 +1,182 @@
+package screens
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var clusterTotalsColumns = []struct {
+	header string
+	value  func(t core.ClusterTotals) string
+}{
+	{"EKS cluster", func(t core.ClusterTotals) string { return t.Cluster }},
+	{"Node groups", func(t core.ClusterTotals) string { return fmt.Sprintf("%d", t.NodeGroups) }},
+	{"Current costs", func(t core.ClusterTotals) string { return fmt.Sprintf("%.2f", t.CurrentCosts) }},
+	{"Projected costs", func(t core.ClusterTotals) string { return fmt.Sprintf("%.2f", t.ProjectedCosts) }},
+	{"Spot savings", func(t core.ClusterTotals) string { return fmt.Sprintf("%.2f", t.SpotSavings) }},
+	{"Spot savings %", func(t core.ClusterTotals) string { return fmt.Sprintf("%d%%", int(t.SpotSavingsPercent)) }},
+}
+
+// showClusterTotalsDialog displays the totals of the EKS managed node groups
+// matching the current filter, for each cluster.
+func showClusterTotalsDialog(w fyne.Window, c *core.Launcher) {
+	clusters := c.ClusterTotals()
+	if len(clusters) == 0 {
+		dialog.ShowInformation("EKS cluster totals",
+			"None of the AutoScaling groups matching the filter belong to EKS managed node groups.", w)
+		return
+	}
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(clusters), len(clusterTotalsColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(clusterTotalsColumns[id.Col].value(clusters[id.Row]))
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col >= 0 && id.Col < len(clusterTotalsColumns) {
+			header.SetText(clusterTotalsColumns[id.Col].header)
+		}
+	}
+	table.SetColumnWidth(0, 250)
+	for i := 1; i < len(clusterTotalsColumns); i++ {
+		table.SetColumnWidth(i, 140)
+	}
+
+	d := dialog.NewCustom(fmt.Sprintf("EKS cluster totals (%s, %s)", c.PricingInterval(), c.CurrencyCode()), "Close", table, w)
+	d.Resize(fyne.NewSize(1000, 400))
+	d.Show()
+}
+
+var karpenterColumns = []struct {
+	header string
+	value  func(n core.KarpenterNodeGroup) string
+}{
+	{"Node group", func(n core.KarpenterNodeGroup) string { return n.NodeGroup }},
+	{"Current costs", func(n core.KarpenterNodeGroup) string { return fmt.Sprintf("%.2f", n.CurrentCosts) }},
+	{"AutoSpotting net savings", func(n core.KarpenterNodeGroup) string { return fmt.Sprintf("%.2f", n.AutoSpottingNetSavings) }},
+	{"Karpenter savings", func(n core.KarpenterNodeGroup) string { return fmt.Sprintf("%.2f", n.KarpenterSavings) }},
+}
+
+// showKarpenterDialog generates the Karpenter configuration for the managed
+// node groups of an EKS cluster, and compares its savings with AutoSpotting.
+func showKarpenterDialog(w fyne.Window, c *core.Launcher) {
+	var clusters []string
+	for _, t := range c.ClusterTotals() {
+		clusters = append(clusters, t.Cluster)
+	}
+	if len(clusters) == 0 {
+		dialog.ShowInformation("Karpenter configuration",
+			"None of the AutoScaling groups matching the filter belong to EKS managed node groups.", w)
+		return
+	}
+
+	var config *core.KarpenterConfig
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			if config == nil {
+				return 0, 0
+			}
+			return len(config.NodeGroups), len(karpenterColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(karpenterColumns[id.Col].value(config.NodeGroups[id.Row]))
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col >= 0 && id.Col < len(karpenterColumns) {
+			header.SetText(karpenterColumns[id.Col].header)
+		}
+	}
+	table.SetColumnWidth(0, 250)
+	for i := 1; i < len(karpenterColumns); i++ {
+		table.SetColumnWidth(i, 200)
+	}
+
+	yamlText := widget.NewMultiLineEntry()
+	yamlText.TextStyle.Monospace = true
+
+	save := widget.NewButton("Save YAML", func() {
+		if config == nil {
+			return
+		}
+		d := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uc == nil {
+				return
+			}
+			defer uc.Close()
+
+			if _, err := uc.Write([]byte(yamlText.Text)); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			log.Println("Saved the Karpenter configuration to", uc.URI())
+		}, w)
+		d.SetFileName(fmt.Sprintf("karpenter-%s.yaml", config.Cluster))
+		d.Show()
+	})
+	save.Disable()
+
+	warnings := widget.NewLabel("")
+	warnings.Importance = widget.WarningImportance
+	warnings.Wrapping = fyne.TextWrapWord
+	warnings.Hide()
+
+	cluster := widget.NewSelect(clusters, func(s string) {
+		result, err := c.KarpenterConfig(s)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		config = result
+		yamlText.SetText(config.YAML)
+		warnings.SetText(strings.Join(config.Warnings, "\n"))
+		if len(config.Warnings) > 0 {
+			warnings.Show()
+		} else {
+			warnings.Hide()
+		}
+		table.Refresh()
+		save.Enable()
+	})
+
+	comparison := container.NewVScroll(table)
+	comparison.SetMinSize(fyne.NewSize(0, 150))
+
+	help := widget.NewLabel(fmt.Sprintf("Savings are %s, in %s. The AutoSpotting fee is calculated for the whole cluster "+
+		"and split by the savings of each node group. The Karpenter savings assume the same instance types, "+
+		"all running on Spot, without any fee. The EC2NodeClass role follows the KarpenterNodeRole-<cluster> naming "+
+		"convention, adjust it if your Karpenter node role is named differently.", c.PricingInterval(), c.CurrencyCode()))
+	help.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewCustom("Karpenter configuration", "Close", container.NewBorder(
+		container.NewVBox(container.NewBorder(nil, nil, widget.NewLabel("EKS cluster"), save, cluster), help, warnings, comparison),
+		nil, nil, nil, yamlText), w)
+	d.Resize(fyne.NewSize(1000, 700))
+	d.Show()
+}
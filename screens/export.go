@@ -0,0 +1,61 @@
+package screens
+
+import (
+	"log"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var exportFormatOptions = []string{"CSV (spreadsheets)", "JSON (machine-readable)", "Markdown", "HTML report", "PDF report"}
+
+var exportFormatNames = map[string]core.ExportFormat{
+	exportFormatOptions[0]: core.ExportCSV,
+	exportFormatOptions[1]: core.ExportJSON,
+	exportFormatOptions[2]: core.ExportMarkdown,
+	exportFormatOptions[3]: core.ExportHTML,
+	exportFormatOptions[4]: core.ExportPDF,
+}
+
+// showExportDialog exports the estimate of the selected ASGs to a file in the
+// format chosen by the user.
+func showExportDialog(w fyne.Window, c *core.Launcher) {
+	estimate, err := c.Estimate()
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	format := widget.NewRadioGroup(exportFormatOptions, nil)
+	format.SetSelected(exportFormatOptions[0])
+	format.Required = true
+
+	dialog.ShowCustomConfirm("Export estimate", "Export", "Cancel", format, func(ok bool) {
+		if !ok {
+			return
+		}
+		f := exportFormatNames[format.Selected]
+
+		save := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uc == nil {
+				return
+			}
+			defer uc.Close()
+
+			if err := estimate.Export(uc, f); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			log.Println("Exported the estimate to", uc.URI())
+		}, w)
+		save.SetFileName(estimate.FileName(f))
+		save.Show()
+	}, w)
+}
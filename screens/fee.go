@@ -0,0 +1,132 @@
+package screens
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	preferenceFeeModel = "FeeModel"
+	preferenceFeeValue = "FeeValue"
+)
+
+var feeModelNames = map[core.FeeModelType]string{
+	core.FeeModelAutoSpotting: "AutoSpotting (AWS Marketplace)",
+	core.FeeModelPercentage:   "Percentage of savings",
+	core.FeeModelFlat:         "Flat monthly fee",
+	core.FeeModelNone:         "None (native ASG Spot)",
+}
+
+var feeValuePlaceHolders = map[core.FeeModelType]string{
+	core.FeeModelPercentage: "% of savings",
+	core.FeeModelFlat:       "Monthly amount in USD",
+}
+
+// feeModelSettings holds the widgets used for selecting the fee model, which
+// is persisted in the preferences.
+type feeModelSettings struct {
+	c        *core.Launcher
+	model    *widget.Select
+	value    *widget.Entry
+	syncing  bool
+	onChange func()
+}
+
+func newFeeModelSettings(c *core.Launcher, onChange func()) *feeModelSettings {
+	f := &feeModelSettings{c: c, onChange: onChange}
+
+	var options []string
+	byName := map[string]core.FeeModelType{}
+	for _, t := range core.FeeModelTypes {
+		options = append(options, feeModelNames[t])
+		byName[feeModelNames[t]] = t
+	}
+
+	f.value = widget.NewEntry()
+	f.value.Validator = validation.NewRegexp(`^[0-9]+(\.[0-9]+)?$`, "Must be a positive number")
+	f.value.OnChanged = func(string) { f.apply(byName[f.model.Selected]) }
+
+	f.model = widget.NewSelect(options, func(s string) { f.apply(byName[s]) })
+
+	prefs := fyne.CurrentApp().Preferences()
+	fee := core.FeeConfig{
+		Type:  core.FeeModelType(prefs.StringWithFallback(preferenceFeeModel, string(core.FeeModelAutoSpotting))),
+		Value: prefs.Float(preferenceFeeValue),
+	}
+	if err := c.SetFeeModel(fee); err != nil {
+		c.SetFeeModel(core.FeeConfig{Type: core.FeeModelAutoSpotting})
+	}
+	f.Sync()
+
+	return f
+}
+
+// apply selects the fee model from the widgets, unless the value is invalid.
+func (f *feeModelSettings) apply(t core.FeeModelType) {
+	if f.syncing {
+		return
+	}
+
+	f.updateValueEntry(t)
+
+	fee := core.FeeConfig{Type: t}
+	if !f.value.Disabled() {
+		if f.value.Validate() != nil {
+			return
+		}
+		fee.Value, _ = strconv.ParseFloat(f.value.Text, 64)
+	}
+
+	if err := f.c.SetFeeModel(fee); err != nil {
+		return
+	}
+
+	prefs := fyne.CurrentApp().Preferences()
+	prefs.SetString(preferenceFeeModel, string(fee.Type))
+	prefs.SetFloat(preferenceFeeValue, fee.Value)
+
+	if f.onChange != nil {
+		f.onChange()
+	}
+}
+
+func (f *feeModelSettings) updateValueEntry(t core.FeeModelType) {
+	if placeHolder, ok := feeValuePlaceHolders[t]; ok {
+		f.value.SetPlaceHolder(placeHolder)
+		f.value.Enable()
+		return
+	}
+	f.value.SetPlaceHolder("")
+	f.value.Disable()
+}
+
+// Sync updates the widgets from the selected fee model, for example after
+// loading a scenario.
+func (f *feeModelSettings) Sync() {
+	f.syncing = true
+	defer func() { f.syncing = false }()
+
+	fee := f.c.Fee
+	if fee.Type == "" {
+		fee.Type = core.FeeModelAutoSpotting
+	}
+	f.model.SetSelected(feeModelNames[fee.Type])
+	f.updateValueEntry(fee.Type)
+
+	text := ""
+	if fee.Value != 0 {
+		text = fmt.Sprintf("%g", fee.Value)
+	}
+	f.value.SetText(text)
+}
+
+func (f *feeModelSettings) content() fyne.CanvasObject {
+	return container.NewGridWithColumns(2, f.model, f.value)
+}
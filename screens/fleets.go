@@ -0,0 +1,132 @@
+package screens
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const preferenceFleetTargetSpot = "FleetTargetSpotPercent"
+
+var fleetColumns = []struct {
+	header   string
+	currency bool
+	value    func(c *core.Launcher, f *core.Fleet, target float64) string
+}{
+	{"Fleet ID", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string { return f.ID }},
+	{"Kind", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string { return f.Kind }},
+	{"Type", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string { return f.Type }},
+	{"Target capacity", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string {
+		return fmt.Sprintf("%d (%d OnDemand)", f.TargetCapacity, f.OnDemandTargetCapacity)
+	}},
+	{"Allocation strategy", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string { return f.AllocationStrategy }},
+	{"Instance pools", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string { return strings.Join(f.InstancePools, ", ") }},
+	{"Running", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string {
+		return fmt.Sprintf("%d Spot, %d OnDemand", f.SpotInstances, f.OnDemandInstances)
+	}},
+	{"Running capacity", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string {
+		return fmt.Sprintf("%g Spot, %g OnDemand", f.SpotCapacity, f.OnDemandCapacity)
+	}},
+	{"Spot % of capacity", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string {
+		return fmt.Sprintf("%.0f%%", f.SpotSharePercent())
+	}},
+	{"Cost", true, func(c *core.Launcher, f *core.Fleet, _ float64) string {
+		return fmt.Sprintf("%.2f", f.HourlyCosts*c.CostMultiplier())
+	}},
+	{"Spot share savings", true, func(c *core.Launcher, f *core.Fleet, target float64) string {
+		return fmt.Sprintf("%.2f", f.SpotShareSavings(target)*c.CostMultiplier())
+	}},
+	{"Pool savings", true, func(c *core.Launcher, f *core.Fleet, _ float64) string {
+		return fmt.Sprintf("%.2f", f.PoolSavings*c.CostMultiplier())
+	}},
+	{"Suggested pools", false, func(_ *core.Launcher, f *core.Fleet, _ float64) string { return strings.Join(f.SuggestedPools, ", ") }},
+}
+
+func fleetsTab(w fyne.Window, c *core.Launcher) *container.TabItem {
+	prefs := fyne.CurrentApp().Preferences()
+	var fleets []*core.Fleet
+	target := 100.0
+
+	targetSpot := widget.NewEntry()
+	targetSpot.Validator = validation.NewRegexp(`^(100|[1-9]?[0-9])$`, "Must be a percentage between 0 and 100")
+	targetSpot.SetText(prefs.StringWithFallback(preferenceFleetTargetSpot, "100"))
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(fleets), len(fleetColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(fleetColumns[id.Col].value(c, fleets[id.Row], target))
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col < 0 || id.Col >= len(fleetColumns) {
+			return
+		}
+		text := fleetColumns[id.Col].header
+		if fleetColumns[id.Col].currency {
+			text += " " + c.CurrencyCode()
+		}
+		header.SetText(text)
+	}
+	for i, width := range []float32{300, 100, 90, 170, 190, 250, 180, 180, 140, 120, 180, 140, 300} {
+		table.SetColumnWidth(i, width)
+	}
+
+	summary := widget.NewLabel("Load the fleets of the current region to see how much of their capacity runs on Spot.")
+	summary.Wrapping = fyne.TextWrapWord
+
+	refresh := func() {
+		if err := targetSpot.Validate(); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		target, _ = strconv.ParseFloat(targetSpot.Text, 64)
+		prefs.SetString(preferenceFleetTargetSpot, targetSpot.Text)
+
+		fleets = c.Fleets()
+		t := c.FleetTotals(target)
+		summary.SetText(fmt.Sprintf("%d active fleets running %d Spot and %d OnDemand instances (%s, %s): current costs %.2f, "+
+			"savings at %.0f%% Spot capacity %.2f, savings from widening the instance pools %.2f.",
+			t.Fleets, t.SpotInstances, t.OnDemandInstances, c.PricingInterval(), c.CurrencyCode(), t.CurrentCosts,
+			target, t.SpotShareSavings, t.PoolSavings))
+		table.Refresh()
+	}
+
+	load := widget.NewButton("Load fleets", func() {
+		progress := dialog.NewCustomWithoutButtons("Loading fleets",
+			widget.NewProgressBarInfinite(), w)
+		progress.Show()
+
+		go func() {
+			err := c.LoadFleets()
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			refresh()
+		}()
+	})
+
+	return container.NewTabItem("Fleets", container.NewBorder(
+		container.NewVBox(
+			container.NewHBox(load, widget.NewButton("Refresh costs", refresh)),
+			widget.NewForm(widget.NewFormItem("Target Spot %", targetSpot)),
+			summary,
+		),
+		nil, nil, nil, table))
+}
@@ -0,0 +1,176 @@
+package screens
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	preferenceForecastMonths        = "ForecastMonths"
+	preferenceForecastDefaultGrowth = "ForecastDefaultGrowth"
+	preferenceForecastGrowthRules   = "ForecastGrowthRules"
+	preferenceForecastRollout       = "ForecastRollout"
+	preferenceForecastMigrationCost = "ForecastMigrationCost"
+)
+
+var forecastColumns = []struct {
+	header   string
+	currency bool
+	value    func(m core.ForecastMonth) string
+}{
+	{"Month", false, func(m core.ForecastMonth) string { return fmt.Sprintf("%d", m.Month) }},
+	{"Rollout %", false, func(m core.ForecastMonth) string { return fmt.Sprintf("%.0f%%", m.RolloutPercent) }},
+	{"Current costs", true, func(m core.ForecastMonth) string { return fmt.Sprintf("%.2f", m.CurrentCosts) }},
+	{"Projected costs", true, func(m core.ForecastMonth) string { return fmt.Sprintf("%.2f", m.ProjectedCosts) }},
+	{"Spot savings", true, func(m core.ForecastMonth) string { return fmt.Sprintf("%.2f", m.SpotSavings) }},
+	{"Fee", true, func(m core.ForecastMonth) string { return fmt.Sprintf("%.2f", m.Fee) }},
+	{"Net savings", true, func(m core.ForecastMonth) string { return fmt.Sprintf("%.2f", m.NetSavings) }},
+	{"Cumulative net savings", true, func(m core.ForecastMonth) string { return fmt.Sprintf("%.2f", m.CumulativeNetSavings) }},
+	{"After migration cost", true, func(m core.ForecastMonth) string { return fmt.Sprintf("%+.2f", m.AfterMigration) }},
+}
+
+func forecast(w fyne.Window, c *core.Launcher) *container.TabItem {
+	prefs := fyne.CurrentApp().Preferences()
+	var f *core.Forecast
+
+	months := widget.NewEntry()
+	months.Validator = validation.NewRegexp(`^[1-9][0-9]*$`, "Must be a positive number of months")
+	months.SetText(prefs.StringWithFallback(preferenceForecastMonths, "12"))
+
+	defaultGrowth := widget.NewEntry()
+	defaultGrowth.Validator = validation.NewRegexp(`^-?[0-9]+(\.[0-9]+)?$`, "Must be a number")
+	defaultGrowth.SetText(prefs.StringWithFallback(preferenceForecastDefaultGrowth, "0"))
+
+	growthRules := widget.NewMultiLineEntry()
+	growthRules.SetPlaceHolder("asg:web-prod,5\ntag:team=data,-2")
+	growthRules.SetMinRowsVisible(3)
+	growthRules.SetText(prefs.String(preferenceForecastGrowthRules))
+
+	rollout := widget.NewEntry()
+	rollout.SetPlaceHolder("1:25, 2:50, 4:100")
+	rollout.SetText(prefs.String(preferenceForecastRollout))
+
+	migrationCost := widget.NewEntry()
+	migrationCost.Validator = validation.NewRegexp(`^[0-9]+(\.[0-9]+)?$`, "Must be a positive number")
+	migrationCost.SetText(prefs.StringWithFallback(preferenceForecastMigrationCost, "0"))
+
+	summary := widget.NewLabel("")
+	summary.Wrapping = fyne.TextWrapWord
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			if f == nil {
+				return 0, 0
+			}
+			return len(f.Months), len(forecastColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(forecastColumns[id.Col].value(f.Months[id.Row]))
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col < 0 || id.Col >= len(forecastColumns) {
+			return
+		}
+		text := forecastColumns[id.Col].header
+		if forecastColumns[id.Col].currency && f != nil {
+			text += " " + f.Currency
+		}
+		header.SetText(text)
+	}
+	for i, col := range forecastColumns {
+		table.SetColumnWidth(i, float32(60+7*len(col.header)))
+	}
+
+	settings := func() (core.ForecastSettings, error) {
+		var s core.ForecastSettings
+		for _, e := range []*widget.Entry{months, defaultGrowth, migrationCost} {
+			if err := e.Validate(); err != nil {
+				return s, err
+			}
+		}
+		s.Months, _ = strconv.Atoi(months.Text)
+		s.DefaultGrowth, _ = strconv.ParseFloat(defaultGrowth.Text, 64)
+		s.MigrationCost, _ = strconv.ParseFloat(migrationCost.Text, 64)
+
+		var err error
+		if s.Growth, err = core.ParseGrowthRules(growthRules.Text); err != nil {
+			return s, err
+		}
+		if s.Rollout, err = core.ParseRolloutSchedule(rollout.Text); err != nil {
+			return s, err
+		}
+
+		prefs.SetString(preferenceForecastMonths, months.Text)
+		prefs.SetString(preferenceForecastDefaultGrowth, defaultGrowth.Text)
+		prefs.SetString(preferenceForecastGrowthRules, growthRules.Text)
+		prefs.SetString(preferenceForecastRollout, rollout.Text)
+		prefs.SetString(preferenceForecastMigrationCost, migrationCost.Text)
+		return s, nil
+	}
+
+	run := widget.NewButton("Forecast", func() {
+		s, err := settings()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		result, err := c.Forecast(s)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		f = result
+
+		last := f.Months[len(f.Months)-1]
+		text := fmt.Sprintf("Cumulative net savings over %d months: %.2f %s.", len(f.Months), last.CumulativeNetSavings, f.Currency)
+		switch {
+		case s.MigrationCost == 0:
+		case f.BreakEvenMonth > 0:
+			text += fmt.Sprintf(" The migration cost of %.2f %s is recovered in month %d.", s.MigrationCost, f.Currency, f.BreakEvenMonth)
+		default:
+			text += fmt.Sprintf(" The migration cost of %.2f %s isn't recovered within the forecast.", s.MigrationCost, f.Currency)
+		}
+		summary.SetText(text)
+		table.Refresh()
+	})
+
+	help := widget.NewButtonWithIcon("", theme.HelpIcon(), func() {
+		text := widget.NewLabel("Growth rules\n\n" + core.ForecastGrowthHelp + "\n\nRollout schedule\n\n" + core.ForecastRolloutHelp)
+		text.TextStyle.Monospace = true
+		dialog.ShowCustom("Forecast settings", "Close", text, w)
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Months", months),
+		widget.NewFormItem("Default monthly growth %", defaultGrowth),
+		widget.NewFormItem("Growth rules", growthRules),
+		widget.NewFormItem("Rollout schedule", rollout),
+		widget.NewFormItem("One-off migration cost", migrationCost),
+	)
+
+	return container.NewTabItem("Forecast", container.NewBorder(
+		container.NewVBox(
+			widget.NewLabel("Projects the monthly costs of the AutoScaling groups matching the current filter, using the fee "+
+				"model and currency selected in the Savings view. The first month uses the current fleet size."),
+			form,
+			container.NewHBox(run, help),
+			summary,
+		),
+		nil, nil, nil, table))
+}
@@ -0,0 +1,124 @@
+package screens
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const preferenceAutoSpottingHorizonDays = "AutoSpottingHorizonDays"
+
+var positiveNumber = regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+// horizonSettings holds the widgets used for selecting the horizon of the
+// costs and savings, which is persisted in the preferences.
+type horizonSettings struct {
+	c        *core.Launcher
+	interval *widget.Select
+	days     *widget.Entry
+	syncing  bool
+	onChange func()
+}
+
+func newHorizonSettings(c *core.Launcher, onChange func()) *horizonSettings {
+	h := &horizonSettings{c: c, onChange: onChange}
+
+	h.days = widget.NewEntry()
+	h.days.SetPlaceHolder("Days")
+	// Fractions of a day such as 0.5 are allowed, like in the core horizon.
+	h.days.Validator = func(text string) error {
+		if !positiveNumber.MatchString(text) {
+			return errors.New("Must be a positive number of days")
+		}
+		if days, _ := strconv.ParseFloat(text, 64); days <= 0 {
+			return errors.New("Must be a positive number of days")
+		}
+		return nil
+	}
+	h.days.OnChanged = func(string) { h.apply(h.interval.Selected) }
+
+	h.interval = widget.NewSelect(core.PricingIntervals, func(s string) { h.apply(s) })
+
+	prefs := fyne.CurrentApp().Preferences()
+	horizon := core.Horizon{
+		Interval: prefs.StringWithFallback(preferenceAutoSpottingPricingInterval, core.IntervalHourly),
+		Days:     prefs.Float(preferenceAutoSpottingHorizonDays),
+	}
+	if err := c.SetHorizon(horizon); err != nil {
+		c.SetPricingInterval(core.IntervalHourly)
+	}
+	h.Sync()
+
+	return h
+}
+
+// apply sets the horizon from the widgets, unless the number of days of a
+// custom horizon is invalid.
+func (h *horizonSettings) apply(interval string) {
+	if h.syncing {
+		return
+	}
+	log.Println("selected pricing interval", interval)
+
+	h.updateDaysEntry(interval)
+
+	horizon := core.Horizon{Interval: interval}
+	if interval == core.IntervalCustom {
+		if h.days.Validate() != nil {
+			return
+		}
+		horizon.Days, _ = strconv.ParseFloat(h.days.Text, 64)
+	}
+
+	if err := h.c.SetHorizon(horizon); err != nil {
+		return
+	}
+
+	prefs := fyne.CurrentApp().Preferences()
+	prefs.SetString(preferenceAutoSpottingPricingInterval, horizon.Interval)
+	prefs.SetFloat(preferenceAutoSpottingHorizonDays, horizon.Days)
+
+	if h.onChange != nil {
+		h.onChange()
+	}
+}
+
+func (h *horizonSettings) updateDaysEntry(interval string) {
+	if interval == core.IntervalCustom {
+		h.days.Enable()
+		return
+	}
+	h.days.Disable()
+}
+
+// Sync updates the widgets from the selected horizon, for example after
+// loading a scenario.
+func (h *horizonSettings) Sync() {
+	h.syncing = true
+	defer func() { h.syncing = false }()
+
+	interval := h.c.Horizon.Interval
+	if interval == "" {
+		interval = core.IntervalHourly
+	}
+	h.interval.SetSelected(interval)
+	h.updateDaysEntry(interval)
+
+	text := ""
+	if h.c.Horizon.Days != 0 {
+		text = fmt.Sprintf("%g", h.c.Horizon.Days)
+	}
+	h.days.SetText(text)
+}
+
+func (h *horizonSettings) content() fyne.CanvasObject {
+	return container.NewGridWithColumns(2, h.interval, h.days)
+}
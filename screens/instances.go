@@ -0,0 +1,108 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var standaloneInstanceColumns = []struct {
+	header   string
+	currency bool
+	value    func(c *core.Launcher, i *core.StandaloneInstance) string
+}{
+	{"Instance ID", false, func(_ *core.Launcher, i *core.StandaloneInstance) string { return i.InstanceID }},
+	{"Name", false, func(_ *core.Launcher, i *core.StandaloneInstance) string { return i.Name }},
+	{"Instance type", false, func(_ *core.Launcher, i *core.StandaloneInstance) string { return i.InstanceType }},
+	{"Platform", false, func(_ *core.Launcher, i *core.StandaloneInstance) string { return i.Platform }},
+	{"Launched", false, func(_ *core.Launcher, i *core.StandaloneInstance) string { return i.LaunchTime.Format("2006-01-02") }},
+	{"Cost", true, func(c *core.Launcher, i *core.StandaloneInstance) string {
+		return fmt.Sprintf("%.2f", i.HourlyCosts*c.CostMultiplier())
+	}},
+	{"Spot Cost", true, func(c *core.Launcher, i *core.StandaloneInstance) string {
+		return fmt.Sprintf("%.2f", i.SpotCosts*c.CostMultiplier())
+	}},
+	{"Spot Savings", true, func(c *core.Launcher, i *core.StandaloneInstance) string {
+		return fmt.Sprintf("%.2f", i.SpotSavings*c.CostMultiplier())
+	}},
+	{"ASG candidate", false, func(_ *core.Launcher, i *core.StandaloneInstance) string {
+		if i.Stateless {
+			return "Looks stateless"
+		}
+		return strings.Join(i.Reasons, ", ")
+	}},
+}
+
+func standaloneInstancesTab(w fyne.Window, c *core.Launcher) *container.TabItem {
+	var instances []*core.StandaloneInstance
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(instances), len(standaloneInstanceColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(standaloneInstanceColumns[id.Col].value(c, instances[id.Row]))
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col < 0 || id.Col >= len(standaloneInstanceColumns) {
+			return
+		}
+		text := standaloneInstanceColumns[id.Col].header
+		if standaloneInstanceColumns[id.Col].currency {
+			text += " " + c.CurrencyCode()
+		}
+		header.SetText(text)
+	}
+	for i, width := range []float32{200, 250, 130, 200, 110, 120, 120, 150, 400} {
+		table.SetColumnWidth(i, width)
+	}
+
+	summary := widget.NewLabel("Load the instances of the current region to find the OnDemand instances running outside " +
+		"of AutoScaling groups and fleets.")
+	summary.Wrapping = fyne.TextWrapWord
+
+	refresh := func() {
+		instances = c.StandaloneInstances()
+		t := c.StandaloneTotals()
+		summary.SetText(fmt.Sprintf("%d standalone instances (%s, %s): current costs %.2f, Spot costs %.2f, Spot savings %.2f. "+
+			"%d of them look stateless enough to move into an AutoScaling group, saving %.2f. Savings are before any fee.",
+			t.Instances, c.PricingInterval(), c.CurrencyCode(), t.CurrentCosts, t.SpotCosts, t.SpotSavings,
+			t.StatelessInstances, t.StatelessSavings))
+		table.Refresh()
+	}
+
+	load := widget.NewButton("Load instances", func() {
+		progress := dialog.NewCustomWithoutButtons("Loading instances",
+			widget.NewProgressBarInfinite(), w)
+		progress.Show()
+
+		go func() {
+			err := c.LoadStandaloneInstances()
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			refresh()
+		}()
+	})
+
+	return container.NewTabItem("Standalone instances", container.NewBorder(
+		container.NewVBox(
+			container.NewHBox(load, widget.NewButton("Refresh costs", refresh)),
+			summary,
+		),
+		nil, nil, nil, table))
+}
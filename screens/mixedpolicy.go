@@ -0,0 +1,256 @@
+package screens
+
+import (
+	"fmt"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Apply modes of the Spot configuration.
+const (
+	applyModeTags   = "AutoSpotting tags"
+	applyModeNative = "Native MixedInstancesPolicy"
+
+	preferenceApplyMode = "ApplyMode"
+)
+
+var policyPlanHeaders = []string{"AutoScaling Group Name", "Setting", "Old value", "New value", "Action"}
+
+type policyPlanRow struct {
+	asg     string
+	skipped string
+	change  *core.PolicyChange
+}
+
+// showPolicyPlanDialog displays the MixedInstancesPolicy changes from the plan
+// and only calls onApply once the user explicitly approves them.
+func showPolicyPlanDialog(w fyne.Window, c *core.Launcher, plan *core.PolicyPlan, onApply func()) {
+	var rows []policyPlanRow
+	var changed, skipped int
+	for _, asgPlan := range plan.ASGs {
+		name := *asgPlan.ASG.AutoScalingGroupName
+		if asgPlan.Skipped != "" {
+			skipped++
+			rows = append(rows, policyPlanRow{asg: name, skipped: asgPlan.Skipped})
+			continue
+		}
+		if len(asgPlan.Pending()) > 0 {
+			changed++
+		}
+		for i := range asgPlan.Changes {
+			rows = append(rows, policyPlanRow{asg: name, change: &asgPlan.Changes[i]})
+		}
+	}
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(rows), len(policyPlanHeaders)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			row := rows[id.Row]
+			label.TextStyle = fyne.TextStyle{}
+
+			if id.Col == 0 {
+				label.SetText(row.asg)
+				return
+			}
+
+			if row.change == nil {
+				text := ""
+				if id.Col == 4 {
+					text = "skipped: " + row.skipped
+				}
+				label.SetText(text)
+				return
+			}
+
+			switch id.Col {
+			case 1:
+				label.SetText(row.change.Setting)
+			case 2:
+				label.SetText(row.change.OldValue)
+			case 3:
+				label.SetText(row.change.NewValue)
+			case 4:
+				label.TextStyle.Bold = row.change.Changed()
+				if row.change.Changed() {
+					label.SetText("change")
+				} else {
+					label.SetText("unchanged")
+				}
+			}
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col >= 0 && id.Col < len(policyPlanHeaders) {
+			header.SetText(policyPlanHeaders[id.Col])
+		}
+	}
+	for i, width := range []float32{300, 220, 250, 250, 300} {
+		table.SetColumnWidth(i, width)
+	}
+
+	summary := widget.NewLabel(fmt.Sprintf(
+		"%d AutoScaling groups to convert, %d already converted and %d skipped in %s.\n"+
+			"The OnDemand number becomes the OnDemand base capacity, and the OnDemand percentage applies above it.\n"+
+			"Projected savings (%s, %s): %.2f with this policy, compared to %.2f with AutoSpotting before its fee.",
+		changed, len(plan.ASGs)-changed-skipped, skipped, plan.Region,
+		c.PricingInterval(), c.CurrencyCode(), plan.NativeSavings, plan.AutoSpottingSavings))
+
+	d := dialog.NewCustomConfirm("Review the MixedInstancesPolicy configuration", "Apply", "Cancel",
+		container.NewBorder(summary, nil, nil, nil, table),
+		func(apply bool) {
+			if apply {
+				onApply()
+			}
+		}, w)
+	d.Resize(fyne.NewSize(1200, 600))
+	d.Show()
+}
+
+// showPolicyRollbackDialog lets the user pick one of the policy snapshots of
+// the current account and region, and restores it after confirmation.
+func showPolicyRollbackDialog(w fyne.Window, c *core.Launcher, onDone func()) {
+	snapshots, err := c.ListPolicySnapshots()
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	if len(snapshots) == 0 {
+		dialog.ShowInformation("Rollback",
+			fmt.Sprintf("There are no MixedInstancesPolicy snapshots for the region %s of this account.", c.CurrentRegion), w)
+		return
+	}
+
+	// The index keeps the options unique even for snapshots taken in the
+	// same second.
+	options := []string{}
+	for i, s := range snapshots {
+		options = append(options, fmt.Sprintf("%d. %s", i+1, s))
+	}
+
+	snapshotSelect := widget.NewSelect(options, nil)
+	snapshotSelect.SetSelectedIndex(0)
+
+	dialog.ShowCustomConfirm("Roll back the MixedInstancesPolicy configuration", "Roll back", "Cancel",
+		container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("The launch template or MixedInstancesPolicy of the %d selected AutoScaling groups "+
+				"will be restored to their state from the chosen snapshot.", len(c.SelectedASGs()))),
+			snapshotSelect,
+		),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := c.RollbackMixedInstancesPolicies(snapshots[snapshotSelect.SelectedIndex()]); err != nil {
+				dialog.ShowError(err, w)
+			} else {
+				dialog.ShowInformation("Rollback", "The MixedInstancesPolicy configuration was restored.", w)
+			}
+			onDone()
+		}, w)
+}
+
+var policyApplyResultHeaders = []string{"AutoScaling Group Name", "Status", "Setting changes", "Error"}
+
+// applyPolicyPlan applies the plan in the background and shows the result for
+// each ASG, allowing to re-run the ASGs that failed. Retries reuse the snapshot
+// taken by the first attempt.
+func applyPolicyPlan(w fyne.Window, c *core.Launcher, plan *core.PolicyPlan, retry bool, onDone func()) {
+	progress := dialog.NewCustomWithoutButtons("Applying the MixedInstancesPolicy configuration",
+		widget.NewProgressBarInfinite(), w)
+	progress.Show()
+
+	go func() {
+		apply := c.ApplyMixedInstancesPolicies
+		if retry {
+			apply = c.RetryMixedInstancesPolicies
+		}
+		results, err := apply(plan)
+		progress.Hide()
+		onDone()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		showPolicyApplyResults(w, c, plan, results, onDone)
+	}()
+}
+
+func showPolicyApplyResults(w fyne.Window, c *core.Launcher, plan *core.PolicyPlan, results []core.PolicyApplyResult, onDone func()) {
+
+	count := map[core.ApplyStatus]int{}
+	for _, r := range results {
+		count[r.Status]++
+	}
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(results), len(policyApplyResultHeaders)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			r := results[id.Row]
+			label.TextStyle = fyne.TextStyle{}
+			switch id.Col {
+			case 0:
+				label.SetText(*r.ASG.AutoScalingGroupName)
+			case 1:
+				label.TextStyle.Bold = r.Status == core.ApplyStatusFailed
+				label.SetText(string(r.Status))
+			case 2:
+				label.SetText(fmt.Sprintf("%d", len(r.Pending())))
+			case 3:
+				text := r.Skipped
+				if r.Err != nil {
+					text = r.Err.Error()
+				}
+				label.SetText(text)
+			}
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col >= 0 && id.Col < len(policyApplyResultHeaders) {
+			header.SetText(policyApplyResultHeaders[id.Col])
+		}
+	}
+	for i, width := range []float32{300, 100, 130, 500} {
+		table.SetColumnWidth(i, width)
+	}
+
+	summary := widget.NewLabel(fmt.Sprintf("%d applied, %d skipped and %d failed.\n\n"+
+		"The AutoScaling groups replace their OnDemand instances with Spot ones as they scale or get refreshed.",
+		count[core.ApplyStatusApplied], count[core.ApplyStatusSkipped], count[core.ApplyStatusFailed]))
+
+	var d dialog.Dialog
+	buttons := container.NewHBox()
+	if count[core.ApplyStatusFailed] > 0 {
+		buttons.Add(widget.NewButton("Retry failed", func() {
+			d.Hide()
+			applyPolicyPlan(w, c, plan.Failed(results), true, onDone)
+		}))
+	}
+	buttons.Add(widget.NewButton("Close", func() { d.Hide() }))
+
+	d = dialog.NewCustomWithoutButtons("MixedInstancesPolicy configuration results",
+		container.NewBorder(summary, container.NewCenter(buttons), nil, nil, table), w)
+	d.Resize(fyne.NewSize(1000, 600))
+	d.Show()
+}
@@ -0,0 +1,192 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var permissionReportHeaders = []string{"Region", "Action", "Features", "Status", "Method", "Details"}
+
+func permissionFeatures(p core.Permission) string {
+	features := []string{}
+	for _, f := range p.Features {
+		features = append(features, string(f))
+	}
+	ret := strings.Join(features, ", ")
+	if p.Optional {
+		ret += " (optional)"
+	}
+	return ret
+}
+
+func permissionsPreflight(w fyne.Window, c *core.Launcher) *container.TabItem {
+	var rows []core.PermissionCheck
+	var rowRegions []string
+
+	summary := widget.NewLabel("Run the check to see if the current credentials have all the permissions needed by the estimator.")
+	summary.Wrapping = fyne.TextWrapWord
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(rows), len(permissionReportHeaders)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			check := rows[id.Row]
+			switch id.Col {
+			case 0:
+				label.SetText(rowRegions[id.Row])
+			case 1:
+				label.SetText(check.Action)
+			case 2:
+				label.SetText(permissionFeatures(check.Permission))
+			case 3:
+				label.SetText(string(check.Status))
+			case 4:
+				label.SetText(check.Method)
+			case 5:
+				label.SetText(check.Detail)
+			}
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col >= 0 && id.Col < len(permissionReportHeaders) {
+			header.SetText(permissionReportHeaders[id.Col])
+		}
+	}
+	for i, width := range []float32{130, 330, 200, 90, 90, 600} {
+		table.SetColumnWidth(i, width)
+	}
+
+	run := widget.NewButton("Run permission check", func() {
+		progress := dialog.NewCustomWithoutButtons("Checking permissions",
+			widget.NewProgressBarInfinite(), w)
+		progress.Show()
+
+		go func() {
+			reports, err := c.CheckPermissions(c.AWSRegions(), core.FeatureEstimate, core.FeatureTagApply)
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+
+			// The results are built aside and swapped in at once, since the
+			// table reads the rows while refreshing.
+			var checks []core.PermissionCheck
+			var regions []string
+			denied := 0
+			for _, report := range reports {
+				for _, check := range report.Checks {
+					checks = append(checks, check)
+					regions = append(regions, report.Region)
+				}
+				for _, check := range report.Denied() {
+					if !check.Optional {
+						denied++
+					}
+				}
+			}
+
+			if denied == 0 {
+				summary.SetText("All the required permissions are granted.")
+			} else {
+				summary.SetText(fmt.Sprintf("%d required permission checks failed, see the details below.", denied))
+			}
+			rows, rowRegions = checks, regions
+			table.Refresh()
+		}()
+	})
+
+	return container.NewTabItem("Permissions", container.NewBorder(
+		container.NewVBox(summary, container.NewHBox(run)), nil, nil, nil, table))
+}
+
+func iamPolicyGenerator(w fyne.Window) *container.TabItem {
+	selected := map[core.Feature]bool{
+		core.FeatureEstimate: true,
+		core.FeatureTagApply: true,
+	}
+
+	output := widget.NewMultiLineEntry()
+	output.TextStyle = fyne.TextStyle{Monospace: true}
+
+	format := widget.NewRadioGroup([]string{"IAM policy", "CloudFormation template"}, nil)
+	format.Horizontal = true
+
+	generate := func() {
+		features := []core.Feature{}
+		for _, f := range core.Features {
+			if selected[f.Feature] {
+				features = append(features, f.Feature)
+			}
+		}
+
+		var out []byte
+		var err error
+		if format.Selected == "CloudFormation template" {
+			out, err = core.CloudFormationTemplate(features)
+		} else {
+			out, err = core.IAMPolicyDocument(features)
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		output.SetText(string(out))
+	}
+
+	checks := container.NewVBox()
+	for _, f := range core.Features {
+		feature := f.Feature
+		check := widget.NewCheck(f.Description, func(checked bool) {
+			selected[feature] = checked
+			generate()
+		})
+		check.SetChecked(selected[feature])
+		checks.Add(check)
+	}
+
+	format.OnChanged = func(string) { generate() }
+	format.SetSelected("IAM policy")
+
+	copyButton := widget.NewButton("Copy to clipboard", func() {
+		w.Clipboard().SetContent(output.Text)
+	})
+
+	saveButton := widget.NewButton("Save", func() {
+		dialog.ShowFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uc == nil {
+				return
+			}
+			defer uc.Close()
+			if _, err := uc.Write([]byte(output.Text)); err != nil {
+				dialog.ShowError(err, w)
+			}
+		}, w)
+	})
+
+	return container.NewTabItem("IAM Policy", container.NewBorder(
+		container.NewVBox(
+			widget.NewLabel("Generate the least-privilege IAM policy for the features you plan to use."),
+			checks,
+			format,
+			container.NewHBox(copyButton, saveButton),
+		), nil, nil, nil, output))
+}
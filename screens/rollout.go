@@ -11,8 +11,10 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/data/validation"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -21,6 +23,9 @@ type widgetType int64
 const (
 	preferenceAutoSpottingRolloutRegion   = "AutoSpottingRolloutRegion"
 	preferenceAutoSpottingPricingInterval = "AutoSpottingPricingInterval"
+	preferenceAutoSpottingSortColumn      = "AutoSpottingSortColumn"
+	preferenceAutoSpottingSortDescending  = "AutoSpottingSortDescending"
+	preferenceGroupByEKSCluster           = "GroupByEKSCluster"
 
 	Label widgetType = iota
 	Check
@@ -33,22 +38,49 @@ type ColumnInfo struct {
 	DataKey        string // New field to identify the data
 	EntryValidator fyne.StringValidator
 	PlaceHolder    string
+	Currency       bool // The header is followed by the selected currency
 }
 
-// ActiveHeader represents a table header that can handle taps.
+// ActiveHeader represents a table header that can handle taps and displays
+// the sort order of its column.
 type ActiveHeader struct {
-	widget.Label
+	widget.BaseWidget
+	Label    *widget.Label
+	Icon     *widget.Icon
 	OnTapped func()
 }
 
 // newActiveHeader creates a new header cell with the specified label.
 func newActiveHeader(label string) *ActiveHeader {
-	h := &ActiveHeader{}
+	h := &ActiveHeader{
+		Label: widget.NewLabel(label),
+		Icon:  widget.NewIcon(nil),
+	}
+	h.Icon.Hide()
 	h.ExtendBaseWidget(h)
-	h.SetText(label)
 	return h
 }
 
+// CreateRenderer displays the sort indicator right after the label.
+func (h *ActiveHeader) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewHBox(h.Label, h.Icon))
+}
+
+// SetSortIndicator shows the sort direction, or hides the indicator if the
+// table isn't sorted by this column.
+func (h *ActiveHeader) SetSortIndicator(sorted, descending bool) {
+	switch {
+	case !sorted:
+		h.Icon.Hide()
+	case descending:
+		h.Icon.SetResource(theme.MenuDropDownIcon())
+		h.Icon.Show()
+	default:
+		h.Icon.SetResource(theme.MenuDropUpIcon())
+		h.Icon.Show()
+	}
+}
+
 // Tapped is called when the header is tapped.
 func (h *ActiveHeader) Tapped(_ *fyne.PointEvent) {
 	if h.OnTapped != nil {
@@ -60,9 +92,131 @@ func (h *ActiveHeader) Tapped(_ *fyne.PointEvent) {
 func (h *ActiveHeader) TappedSecondary(_ *fyne.PointEvent) {
 }
 
-func autoSpottingRollout(a fyne.App, w fyne.Window, c *core.Launcher) *container.TabItem {
+func autoSpottingRollout(a fyne.App, w fyne.Window, c *core.Launcher, view *asgTableView) *container.TabItem {
+
+	selection := widget.NewLabel("")
+	refreshSelection := func() {
+		selection.SetText(fmt.Sprintf("%d of %d AutoScaling groups selected",
+			len(c.SelectedASGs()), len(c.VisibleASGs())))
+	}
+	view.onRefresh = refreshSelection
+
+	filter := widget.NewEntry()
+	view.filter = filter
+	filter.SetPlaceHolder("Filter by name, /regex/, tag=value or metrics such as cost>5 or savings%<20")
+	filter.Validator = func(s string) error {
+		_, err := core.ParseASGFilter(s)
+		return err
+	}
+	filter.OnChanged = func(s string) {
+		f, err := core.ParseASGFilter(s)
+		if err != nil {
+			return
+		}
+		c.ASGFilter = f
+		view.Refresh()
+	}
+
+	filterHelp := widget.NewButtonWithIcon("", theme.HelpIcon(), func() {
+		help := widget.NewLabel(core.ASGFilterHelp)
+		help.TextStyle = fyne.TextStyle{Monospace: true}
+		dialog.ShowCustom("Filter syntax", "Close", help, w)
+	})
+
+	selectionButtons := container.NewHBox(
+		widget.NewButton("Select all", func() {
+			c.SelectAll()
+			view.Refresh()
+		}),
+		widget.NewButton("Select filtered", func() {
+			c.SelectVisible()
+			view.Refresh()
+		}),
+		widget.NewButton("Invert selection", func() {
+			c.InvertSelection()
+			view.Refresh()
+		}),
+		selection,
+	)
+
+	c.GroupByCluster = a.Preferences().Bool(preferenceGroupByEKSCluster)
+	groupByCluster := widget.NewCheck("Group by EKS cluster", func(set bool) {
+		c.GroupByCluster = set
+		a.Preferences().SetBool(preferenceGroupByEKSCluster, set)
+		view.Refresh()
+	})
+	groupByCluster.SetChecked(c.GroupByCluster)
+
+	clusterButtons := container.NewHBox(
+		groupByCluster,
+		widget.NewButton("EKS cluster totals", func() {
+			showClusterTotalsDialog(w, c)
+		}),
+		widget.NewButton("Karpenter config", func() {
+			showKarpenterDialog(w, c)
+		}),
+	)
+
+	return container.NewTabItem("Convert ASGs to Spot", container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, filterHelp, selectionButtons, filter),
+			clusterButtons,
+		),
+		nil, nil, nil, view.table))
+}
+
+// asgTableView holds the ASGs currently displayed by the ASG table.
+type asgTableView struct {
+	c         *core.Launcher
+	table     *widget.Table
+	rows      []*core.ASG
+	filter    *widget.Entry
+	onRefresh func()
+}
+
+// sortBy sorts the table by the given column, toggling between ascending and
+// descending order when it's already sorted by that column. The sort order
+// is persisted in the preferences.
+func (v *asgTableView) sortBy(key string) {
+	if v.c.ASGSort.Key == key {
+		v.c.ASGSort.Descending = !v.c.ASGSort.Descending
+	} else {
+		v.c.ASGSort = core.ASGSort{Key: key}
+	}
+	v.saveSort()
+	v.Refresh()
+}
+
+func (v *asgTableView) saveSort() {
+	prefs := fyne.CurrentApp().Preferences()
+	prefs.SetString(preferenceAutoSpottingSortColumn, v.c.ASGSort.Key)
+	prefs.SetBool(preferenceAutoSpottingSortDescending, v.c.ASGSort.Descending)
+}
+
+// loadScenario updates the widgets holding global settings after loading a
+// scenario, and redraws the table.
+func (v *asgTableView) loadScenario(s *core.Scenario, horizon *horizonSettings, fee *feeModelSettings,
+	currency *currencySettings, syncECS func()) {
+	horizon.Sync()
+	horizon.onChange()
+	fee.Sync()
+	currency.Sync()
+	syncECS()
+	if v.filter != nil {
+		v.filter.SetText(s.Settings.Filter)
+	}
+	v.saveSort()
+	v.Refresh()
+}
 
-	return container.NewTabItem("Convert ASGs to Spot", makeASGTable(w, c))
+// Refresh recomputes the displayed ASGs and redraws the table.
+func (v *asgTableView) Refresh() {
+	v.rows = v.c.VisibleASGs()
+	v.table.Refresh()
+	v.c.UpdateAutoSpottingTotals(v.c.CurrentRegion)
+	if v.onRefresh != nil {
+		v.onRefresh()
+	}
 }
 
 func formatFloat(f float64) string {
@@ -77,12 +231,14 @@ func formatFloat(f float64) string {
 
 func getColumnInfoData() []ColumnInfo {
 	return []ColumnInfo{
+		{Header: "Selected", Type: Check, DataKey: "Selected"},
 		{Header: "AutoScaling Group Name", Type: Label, DataKey: "AutoScalingGroupName"},
+		{Header: "EKS Node Group", Type: Label, DataKey: "EKSNodeGroup"},
 		{Header: "Instance Type", Type: Label, DataKey: "InstanceTypes"},
 		{Header: "Instances", Type: Label, DataKey: "DesiredCapacity"},
-		{Header: "Cost $", Type: Label, DataKey: "HourlyCosts"},
-		{Header: "Projected Cost $", Type: Label, DataKey: "ProjectedCosts"},
-		{Header: "Projected Savings $", Type: Label, DataKey: "ProjectedSavings"},
+		{Header: "Cost", Type: Label, DataKey: "HourlyCosts", Currency: true},
+		{Header: "Projected Cost", Type: Label, DataKey: "ProjectedCosts", Currency: true},
+		{Header: "Projected Savings", Type: Label, DataKey: "ProjectedSavings", Currency: true},
 		{Header: "Projected Savings %", Type: Label, DataKey: "ProjectedSavingsPercent"},
 		{Header: "OnDemand %", Type: Entry, DataKey: "OnDemandPercentage", EntryValidator: validation.NewRegexp(`^([0-9]|[1-9][0-9]|100)$`, "Must contain an integer number between 0 and 100"), PlaceHolder: "0-100"},
 		{Header: "OnDemand #", Type: Entry, DataKey: "OnDemandNumber", EntryValidator: validation.NewRegexp(`^([0-9]|[1-9][0-9]+)$`, "Must contain a natural number"), PlaceHolder: "Number"},
@@ -90,15 +246,10 @@ func getColumnInfoData() []ColumnInfo {
 	}
 }
 
-func createTableWithHeaders(c *core.Launcher, data []ColumnInfo) *widget.Table {
+func createTableWithHeaders(view *asgTableView, data []ColumnInfo) *widget.Table {
 	t := widget.NewTableWithHeaders(
 		func() (int, int) {
-			if c.Regions == nil || c.Regions[c.CurrentRegion] == nil ||
-				c.Regions[c.CurrentRegion].AutoSpotting == nil {
-				return 0, 0
-			} else {
-				return len(c.Regions[c.CurrentRegion].AutoSpotting.ASGs), len(data)
-			}
+			return len(view.rows), len(data)
 		},
 		func() fyne.CanvasObject {
 			return container.NewStack(
@@ -114,34 +265,63 @@ func createTableWithHeaders(c *core.Launcher, data []ColumnInfo) *widget.Table {
 
 	t.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
 		header := o.(*ActiveHeader)
-		header.TextStyle.Bold = true
-		if id.Col >= 0 && id.Col < len(data) {
-			header.SetText(data[id.Col].Header)
+		header.Label.TextStyle.Bold = true
+		header.OnTapped = nil
+		header.SetSortIndicator(false, false)
+
+		if id.Row >= 0 {
+			header.Label.SetText(fmt.Sprintf("%d", id.Row+1))
+			return
 		}
 
+		if id.Col < 0 || id.Col >= len(data) {
+			return
+		}
+
+		key := data[id.Col].DataKey
+		text := data[id.Col].Header
+		if data[id.Col].Currency {
+			text += " " + view.c.CurrencyCode()
+		}
+		header.Label.SetText(text)
+		header.SetSortIndicator(view.c.ASGSort.Key == key, view.c.ASGSort.Descending)
+
 		header.OnTapped = func() {
-			log.Printf("Header %d tapped\n", id.Col)
+			log.Printf("Header %d tapped, sorting by %s\n", id.Col, key)
+			view.sortBy(key)
 		}
 	}
 	return t
 }
-func makeASGTable(w fyne.Window, c *core.Launcher) fyne.CanvasObject {
+func makeASGTable(w fyne.Window, c *core.Launcher) *asgTableView {
 	data := getColumnInfoData()
-	t := createTableWithHeaders(c, data)
+	view := &asgTableView{c: c}
+
+	prefs := fyne.CurrentApp().Preferences()
+	c.ASGSort = core.ASGSort{
+		Key:        prefs.String(preferenceAutoSpottingSortColumn),
+		Descending: prefs.Bool(preferenceAutoSpottingSortDescending),
+	}
+	t := createTableWithHeaders(view, data)
+	view.table = t
 
 	t.UpdateCell = func(id widget.TableCellID, o fyne.CanvasObject) {
-		generateAutoSpottingTableData(&o, &id, &data, c)
+		generateAutoSpottingTableData(&o, &id, &data, c, view)
 		c.UpdateAutoSpottingTotals(c.CurrentRegion)
 	}
 
 	for i, col := range data {
-		t.SetColumnWidth(i, float32(30+7*len(col.Header)))
+		width := len(col.Header)
+		if col.Currency {
+			width += 4
+		}
+		t.SetColumnWidth(i, float32(30+7*width))
 	}
 
-	return t
+	return view
 }
 
-func generateAutoSpottingTableData(o *fyne.CanvasObject, id *widget.TableCellID, data *[]ColumnInfo, c *core.Launcher) {
+func generateAutoSpottingTableData(o *fyne.CanvasObject, id *widget.TableCellID, data *[]ColumnInfo, c *core.Launcher, view *asgTableView) {
 	container := (*o).(*fyne.Container)
 	label := container.Objects[0].(*widget.Label)
 	check := container.Objects[1].(*widget.Check)
@@ -152,12 +332,12 @@ func generateAutoSpottingTableData(o *fyne.CanvasObject, id *widget.TableCellID,
 	check.Hide()
 	entry.Hide()
 
-	if id.Row < 0 || id.Row >= len(c.Regions[c.CurrentRegion].AutoSpotting.ASGs) {
+	if id.Row < 0 || id.Row >= len(view.rows) {
 		return
 	}
 
 	colInfo := (*data)[id.Col]
-	asg := c.Regions[c.CurrentRegion].AutoSpotting.ASGs[id.Row]
+	asg := view.rows[id.Row]
 
 	// Determine cell width for this column (you might have this set elsewhere in your app)
 	// cellWidth := int(t.ColumnWidth(id.Col)) - cellPadding
@@ -171,22 +351,22 @@ func generateAutoSpottingTableData(o *fyne.CanvasObject, id *widget.TableCellID,
 		switch colInfo.DataKey {
 		case "AutoScalingGroupName":
 			text = *asg.AutoScalingGroupName
+		case "EKSNodeGroup":
+			if asg.IsManagedNodeGroup() {
+				text = asg.EKSCluster + "/" + asg.EKSNodeGroup
+			}
 		case "InstanceTypes":
 			text = strings.Join(asg.InstanceTypes, ",")
 		case "DesiredCapacity":
 			text = fmt.Sprintf("%d", *asg.DesiredCapacity)
 		case "HourlyCosts":
-			text = formatFloat(asg.HourlyCosts * c.PricingIntervalMultiplier)
+			text = formatFloat(asg.HourlyCosts * c.CostMultiplier())
 		case "ProjectedCosts":
-			text = formatFloat(asg.ProjectedCosts * c.PricingIntervalMultiplier)
+			text = formatFloat(asg.ProjectedCosts * c.CostMultiplier())
 		case "ProjectedSavings":
-			text = formatFloat(asg.ProjectedSavings * c.PricingIntervalMultiplier)
+			text = formatFloat(asg.ProjectedSavings * c.CostMultiplier())
 		case "ProjectedSavingsPercent":
-			percentage := 0
-			if asg.HourlyCosts > 0 {
-				percentage = int(asg.ProjectedSavings / asg.HourlyCosts * 100)
-			}
-			text = fmt.Sprintf("%d%%", percentage)
+			text = fmt.Sprintf("%d%%", int(asg.ProjectedSavingsPercent()))
 		}
 		// truncatedText := truncateTextToFitCell(text, maxChars)
 
@@ -194,8 +374,21 @@ func generateAutoSpottingTableData(o *fyne.CanvasObject, id *widget.TableCellID,
 		label.SetText(text)
 		label.Show()
 	case Check:
+		if colInfo.DataKey == "Selected" {
+			check.Show()
+			// Detach the handler bound to the row this cell displayed before
+			check.OnChanged = nil
+			check.SetChecked(asg.Selected)
+			check.OnChanged = func(checked bool) {
+				asg.Selected = checked
+				if view.onRefresh != nil {
+					view.onRefresh()
+				}
+			}
+		}
 		if colInfo.DataKey == "Enabled" {
 			check.Show()
+			check.OnChanged = nil
 			check.SetChecked(asg.Enabled)
 			check.OnChanged = func(checked bool) {
 				// Update the ASG Enabled status based on checkbox
@@ -214,6 +407,7 @@ func generateAutoSpottingTableData(o *fyne.CanvasObject, id *widget.TableCellID,
 		case "OnDemandNumber":
 			entryText = fmt.Sprintf("%d", asg.OnDemandNumber)
 		}
+		entry.OnChanged = nil
 		entry.SetText(entryText)
 		entry.OnChanged = func(text string) {
 			switch colInfo.DataKey {
@@ -285,13 +479,18 @@ func rollout(w fyne.Window, c *core.Launcher) fyne.CanvasObject {
 
 	a := fyne.CurrentApp()
 
+	asgTable := makeASGTable(w, c)
+
 	regions := widget.NewSelect(c.AWSRegions(), func(s string) {
 		a.Preferences().SetString(preferenceAutoSpottingRolloutRegion, s)
 		log.Println("selected AWS region", s)
 
 		if p := a.Preferences().String(preferenceProfile); p != "" {
 			log.Println("selected profile", p)
-			c.ConnectWithProfileAuth(p)
+			if err := c.ConnectWithProfileAuth(p); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
 			c.SetRegion(p)
 		}
 
@@ -313,19 +512,56 @@ func rollout(w fyne.Window, c *core.Launcher) fyne.CanvasObject {
 		}
 		c.Regions[s].AutoSpotting.LoadASGData()
 		c.SetRegion(s)
+		asgTable.Refresh()
 	})
 
-	priceMode := widget.NewSelect([]string{"hourly", "monthly"}, func(s string) {
-		a.Preferences().SetString(preferenceAutoSpottingPricingInterval, s)
-		log.Println("selected pricing interval", s)
+	costTotals := &widget.Form{
+		Items: []*widget.FormItem{
+			{Widget: widget.NewLabelWithData(c.AutoSpottingCurrentTotalMonthlyCosts), HintText: ""},
+			{Widget: widget.NewLabelWithData(c.AutoSpottingProjectedMonthlyCosts), HintText: ""},
+		},
+	}
+	savingsTotals := &widget.Form{
+		Items: []*widget.FormItem{
+			{Widget: widget.NewLabelWithData(c.AutoSpottingProjectedSpotSavings), HintText: ""},
+			{Text: "Total projected Spot savings percentage", Widget: widget.NewLabelWithData(
+				c.AutoSpottingProjectedSpotSavingsPercent), HintText: ""},
+		},
+	}
+	feeTotals := &widget.Form{
+		Items: []*widget.FormItem{
+			{Widget: widget.NewLabelWithData(c.AutoSpottingProjectedAutoSpottingCharges), HintText: ""},
+			{Widget: widget.NewLabelWithData(c.AutoSpottingProjectedNetSavings), HintText: ""},
+		},
+	}
+
+	// The labels of the totals follow the selected horizon, currency and fee
+	// model.
+	updateTotalsLabels := func() {
+		interval, currency := c.PricingInterval(), c.CurrencyCode()
+		costTotals.Items[0].Text = fmt.Sprintf("Total current %s costs (%s)", interval, currency)
+		costTotals.Items[1].Text = fmt.Sprintf("Total projected %s costs (%s)", interval, currency)
+		savingsTotals.Items[0].Text = fmt.Sprintf("Total projected Spot %s savings (%s)", interval, currency)
+		feeTotals.Items[0].Text, _ = c.FeeLabel.Get()
+		feeTotals.Items[1].Text = fmt.Sprintf("Total %s net savings (%s)", interval, currency)
+		costTotals.Refresh()
+		savingsTotals.Refresh()
+		feeTotals.Refresh()
+	}
 
-		c.SetPricingInterval(s)
+	horizon := newHorizonSettings(c, func() {
+		updateTotalsLabels()
+		asgTable.Refresh()
 	})
 
-	pref := a.Preferences().String(preferenceAutoSpottingPricingInterval)
-	if pref != "" {
-		priceMode.SetSelected(pref)
-	}
+	fee := newFeeModelSettings(c, asgTable.Refresh)
+	currency := newCurrencySettings(w, c, func() {
+		updateTotalsLabels()
+		asgTable.Refresh()
+	})
+	loadDiscounts(c)
+	ecs, syncECS := ecsTab(w, c)
+	c.FeeLabel.AddListener(binding.NewDataListener(updateTotalsLabels))
 
 	odPercentage := widget.NewEntry()
 	odPercentage.Validator = validation.NewRegexp(`^([0-9]|[1-9][0-9]|100)$`, "0 - 100")
@@ -383,19 +619,22 @@ func rollout(w fyne.Window, c *core.Launcher) fyne.CanvasObject {
 		}
 	})
 
+	applyMode := widget.NewSelect([]string{applyModeTags, applyModeNative}, func(mode string) {
+		fyne.CurrentApp().Preferences().SetString(preferenceApplyMode, mode)
+	})
+	applyMode.SetSelected(fyne.CurrentApp().Preferences().StringWithFallback(preferenceApplyMode, applyModeTags))
+
 	return container.NewBorder(
 		container.NewHBox(
 			container.NewGridWithRows(2,
 				&widget.Form{
 					Items: []*widget.FormItem{
 						{Text: "AWS Region", Widget: regions, HintText: ""},
-						//{Text: "Pricing interval", Widget: priceMode, HintText: ""},
-
 					}},
 				&widget.Form{
 					Items: []*widget.FormItem{
 						//	{Text: "AWS Region", Widget: regions, HintText: ""},
-						{Text: "Pricing interval", Widget: priceMode, HintText: ""},
+						{Text: "Pricing interval", Widget: horizon.content(), HintText: ""},
 					}},
 
 				&widget.Form{
@@ -416,6 +655,20 @@ func rollout(w fyne.Window, c *core.Launcher) fyne.CanvasObject {
 						//	{Text: "Override OnDemand Number", Widget: odNumber, HintText: ""},
 
 					}},
+				&widget.Form{
+					Items: []*widget.FormItem{
+						{Text: "Fee model", Widget: fee.content(), HintText: ""},
+					}},
+				&widget.Form{
+					Items: []*widget.FormItem{
+						{Text: "Currency", Widget: currency.content(), HintText: ""},
+					}},
+				&widget.Form{
+					Items: []*widget.FormItem{
+						{Text: "Price adjustments", Widget: widget.NewButton("Discounts", func() {
+							showDiscountsDialog(w, c, asgTable.Refresh)
+						}), HintText: ""},
+					}},
 
 				// &widget.Form{
 				// 	Items: []*widget.FormItem{
@@ -426,42 +679,54 @@ func rollout(w fyne.Window, c *core.Launcher) fyne.CanvasObject {
 		container.NewBorder(
 			nil, nil, nil,
 			container.NewHBox(
-				&widget.Form{
-					Items: []*widget.FormItem{
-						{Text: "Total current monthly costs", Widget: widget.NewLabelWithData(
-							c.AutoSpottingCurrentTotalMonthlyCosts), HintText: ""},
-						{Text: "Total projected monthly costs", Widget: widget.NewLabelWithData(
-							c.AutoSpottingProjectedMonthlyCosts), HintText: ""},
-					},
-				},
+				costTotals,
+				savingsTotals,
+				feeTotals,
 				&widget.Form{
 					Items: []*widget.FormItem{
 
-						{Text: "Total projected Spot monthly savings", Widget: widget.NewLabelWithData(
-							c.AutoSpottingProjectedSpotSavings), HintText: ""},
-						{Text: "Total projected Spot savings percentage", Widget: widget.NewLabelWithData(
-							c.AutoSpottingProjectedSpotSavingsPercent), HintText: ""},
-					},
-				},
-				&widget.Form{
-					Items: []*widget.FormItem{
-						{Text: "AutoSpotting charges (~10% of savings)", Widget: widget.NewLabelWithData(
-							c.AutoSpottingProjectedAutoSpottingCharges), HintText: ""},
-						{Text: "Total Monthly net savings", Widget: widget.NewLabelWithData(
-							c.AutoSpottingProjectedNetSavings), HintText: ""},
-					},
-				},
-				&widget.Form{
-					Items: []*widget.FormItem{
-
-						{Text: "", Widget: widget.NewButton("Generate AutoSpotting\n configuration", func() {
-							c.ApplyAutoSpottingTags()
-							dialog.ShowInformation("Information",
-
-								"The configuration was persisted to your AutoScaling group tags. "+
-									"In order for it to be applied, \nyou need to install AutoSpotting "+
-									"from the AWS Marketplace using the link from the Welcome tab...",
-								w)
+						{Text: "", Widget: applyMode, HintText: ""},
+						{Text: "", Widget: widget.NewButton("Generate Spot\n configuration", func() {
+							if applyMode.Selected == applyModeNative {
+								plan, err := c.PlanMixedInstancesPolicies()
+								if err != nil {
+									dialog.ShowError(err, w)
+									return
+								}
+
+								showPolicyPlanDialog(w, c, plan, func() {
+									applyPolicyPlan(w, c, plan, false, asgTable.Refresh)
+								})
+								return
+							}
+
+							plan, err := c.PlanAutoSpottingTags()
+							if err != nil {
+								dialog.ShowError(err, w)
+								return
+							}
+
+							showTagPlanDialog(w, plan, func() {
+								applyTagPlan(w, c, plan, false, asgTable.Refresh)
+							})
+						}), HintText: ""},
+						{Text: "", Widget: widget.NewButton("Rollback", func() {
+							if applyMode.Selected == applyModeNative {
+								showPolicyRollbackDialog(w, c, asgTable.Refresh)
+								return
+							}
+							showRollbackDialog(w, c, asgTable.Refresh)
+						}), HintText: ""},
+						{Text: "", Widget: widget.NewButton("Export", func() {
+							showExportDialog(w, c)
+						}), HintText: ""},
+						{Text: "", Widget: widget.NewButton("Save scenario", func() {
+							showSaveScenarioDialog(w, c)
+						}), HintText: ""},
+						{Text: "", Widget: widget.NewButton("Load scenario", func() {
+							showLoadScenarioDialog(w, c, func(s *core.Scenario) {
+								asgTable.loadScenario(s, horizon, fee, currency, syncECS)
+							})
 						}), HintText: ""},
 					},
 				},
@@ -472,7 +737,12 @@ func rollout(w fyne.Window, c *core.Launcher) fyne.CanvasObject {
 			)),
 		nil, nil,
 		container.NewStack(container.NewAppTabs(
-			autoSpottingRollout(a, w, c),
+			autoSpottingRollout(a, w, c, asgTable),
+			compareScenarios(w, c),
+			forecast(w, c),
+			ecs,
+			standaloneInstancesTab(w, c),
+			fleetsTab(w, c),
 			//ebsOptimizerRollout(a, w, c),
 		)),
 	)
@@ -0,0 +1,123 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showSaveScenarioDialog saves the current ASG configuration and settings as
+// a named scenario, asking before replacing an existing one.
+func showSaveScenarioDialog(w fyne.Window, c *core.Launcher) {
+	existing := map[string]bool{}
+	var names []string
+	if scenarios, err := c.ListScenarios(); err == nil {
+		for _, s := range scenarios {
+			existing[s.Name] = true
+			names = append(names, s.Name)
+		}
+	}
+
+	name := widget.NewSelectEntry(names)
+	name.SetPlaceHolder("Scenario name")
+
+	save := func() {
+		s, err := c.SaveScenario(name.Text)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		dialog.ShowInformation("Save scenario",
+			fmt.Sprintf("Saved the scenario %q with %d AutoScaling groups.", s.Name, len(s.ASGs)), w)
+	}
+
+	dialog.ShowCustomConfirm("Save scenario", "Save", "Cancel",
+		container.NewVBox(
+			widget.NewLabel("Saves the per-ASG overrides and the global settings of the current region."),
+			name,
+		),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			if !existing[strings.TrimSpace(name.Text)] {
+				save()
+				return
+			}
+			dialog.ShowConfirm("Save scenario",
+				fmt.Sprintf("Replace the existing scenario %q?", strings.TrimSpace(name.Text)),
+				func(replace bool) {
+					if replace {
+						save()
+					}
+				}, w)
+		}, w)
+}
+
+// showLoadScenarioDialog loads a saved scenario into the ASG table, then
+// reports the ASGs that changed since it was saved.
+func showLoadScenarioDialog(w fyne.Window, c *core.Launcher, onLoad func(*core.Scenario)) {
+	scenarios, err := c.ListScenarios()
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	if len(scenarios) == 0 {
+		dialog.ShowInformation("Load scenario",
+			fmt.Sprintf("There are no saved scenarios for the region %s of this account.", c.CurrentRegion), w)
+		return
+	}
+
+	options := []string{}
+	byOption := map[string]*core.Scenario{}
+	for _, s := range scenarios {
+		options = append(options, s.String())
+		byOption[s.String()] = s
+	}
+
+	scenarioSelect := widget.NewSelect(options, nil)
+	scenarioSelect.SetSelectedIndex(0)
+
+	dialog.ShowCustomConfirm("Load scenario", "Load", "Cancel",
+		container.NewVBox(
+			widget.NewLabel("Replaces the per-ASG overrides and the global settings with the ones from the scenario."),
+			scenarioSelect,
+		),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			s := byOption[scenarioSelect.Selected]
+			diff, err := c.LoadScenario(s)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			onLoad(s)
+
+			if len(diff.Missing) == 0 && len(diff.New) == 0 {
+				dialog.ShowInformation("Load scenario",
+					fmt.Sprintf("Loaded the scenario %q for all the %d AutoScaling groups.", s.Name, diff.Matched), w)
+				return
+			}
+
+			report := fmt.Sprintf("Loaded the scenario %q for %d AutoScaling groups.", s.Name, diff.Matched)
+			if len(diff.Missing) > 0 {
+				report += fmt.Sprintf("\n\nNo longer existing, ignored:\n%s", strings.Join(diff.Missing, "\n"))
+			}
+			if len(diff.New) > 0 {
+				report += fmt.Sprintf("\n\nCreated since the scenario was saved, left unchanged:\n%s", strings.Join(diff.New, "\n"))
+			}
+			details := widget.NewLabel(report)
+			scroll := container.NewVScroll(details)
+			scroll.SetMinSize(fyne.NewSize(500, 300))
+			dialog.ShowCustom("Load scenario", "Close", scroll, w)
+		}, w)
+}
@@ -0,0 +1,258 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeanerCloud/savings-estimator/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var tagPlanHeaders = []string{"AutoScaling Group Name", "Tag", "Old value", "New value", "Action"}
+
+type tagPlanRow struct {
+	asg    string
+	change *core.TagChange
+}
+
+// showTagPlanDialog displays the tag changes from the plan and only calls
+// onApply once the user explicitly approves them.
+func showTagPlanDialog(w fyne.Window, plan *core.TagPlan, onApply func()) {
+	var rows []tagPlanRow
+	for _, asgPlan := range plan.ASGs {
+		if len(asgPlan.Changes) == 0 {
+			rows = append(rows, tagPlanRow{asg: *asgPlan.ASG.AutoScalingGroupName})
+			continue
+		}
+		for i := range asgPlan.Changes {
+			rows = append(rows, tagPlanRow{
+				asg:    *asgPlan.ASG.AutoScalingGroupName,
+				change: &asgPlan.Changes[i],
+			})
+		}
+	}
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(rows), len(tagPlanHeaders)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			row := rows[id.Row]
+			label.TextStyle = fyne.TextStyle{}
+
+			if id.Col == 0 {
+				label.SetText(row.asg)
+				return
+			}
+
+			if row.change == nil {
+				text := ""
+				if id.Col == 4 {
+					text = "no AutoSpotting tags"
+				}
+				label.SetText(text)
+				return
+			}
+
+			switch id.Col {
+			case 1:
+				label.SetText(row.change.Key)
+			case 2:
+				label.SetText(row.change.OldValue)
+			case 3:
+				label.SetText(row.change.NewValue)
+			case 4:
+				label.TextStyle.Bold = row.change.Action != core.TagActionUnchanged
+				label.SetText(string(row.change.Action))
+			}
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col >= 0 && id.Col < len(tagPlanHeaders) {
+			header.SetText(tagPlanHeaders[id.Col])
+		}
+	}
+	for i, width := range []float32{300, 300, 100, 100, 100} {
+		table.SetColumnWidth(i, width)
+	}
+
+	count := plan.Count()
+	summary := widget.NewLabel(fmt.Sprintf(
+		"%d tags to add, %d to change and %d left alone across %d AutoScaling groups in %s.",
+		count[core.TagActionAdd], count[core.TagActionChange], count[core.TagActionUnchanged],
+		len(plan.ASGs), plan.Region))
+
+	header := container.NewVBox(summary)
+	if nodeGroups := plan.ManagedNodeGroups(); len(nodeGroups) > 0 {
+		var names []string
+		for _, asg := range nodeGroups {
+			names = append(names, fmt.Sprintf("%s (%s/%s)", *asg.AutoScalingGroupName, asg.EKSCluster, asg.EKSNodeGroup))
+		}
+		warning := widget.NewLabel(fmt.Sprintf(
+			"Warning: %d of these AutoScaling groups belong to EKS managed node groups, which should be moved to Spot "+
+				"by creating node groups with the SPOT capacity type instead of AutoSpotting tags: %s",
+			len(nodeGroups), strings.Join(names, ", ")))
+		warning.Importance = widget.WarningImportance
+		warning.Wrapping = fyne.TextWrapWord
+		header.Add(warning)
+	}
+
+	d := dialog.NewCustomConfirm("Review the AutoSpotting configuration", "Apply", "Cancel",
+		container.NewBorder(header, nil, nil, nil, table),
+		func(apply bool) {
+			if apply {
+				onApply()
+			}
+		}, w)
+	d.Resize(fyne.NewSize(1000, 600))
+	d.Show()
+}
+
+// showRollbackDialog lets the user pick one of the tag snapshots of the
+// current account and region, and restores it after confirmation.
+func showRollbackDialog(w fyne.Window, c *core.Launcher, onDone func()) {
+	snapshots, err := c.ListTagSnapshots()
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	if len(snapshots) == 0 {
+		dialog.ShowInformation("Rollback",
+			fmt.Sprintf("There are no tag snapshots for the region %s of this account.", c.CurrentRegion), w)
+		return
+	}
+
+	// The index keeps the options unique even for snapshots taken in the
+	// same second.
+	options := []string{}
+	for i, s := range snapshots {
+		options = append(options, fmt.Sprintf("%d. %s", i+1, s))
+	}
+
+	snapshotSelect := widget.NewSelect(options, nil)
+	snapshotSelect.SetSelectedIndex(0)
+
+	dialog.ShowCustomConfirm("Roll back the AutoSpotting configuration", "Roll back", "Cancel",
+		container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("The AutoSpotting tags of the %d selected AutoScaling groups will be restored "+
+				"to their state from the chosen snapshot.\nTags that didn't exist at the time will be deleted.",
+				len(c.SelectedASGs()))),
+			snapshotSelect,
+		),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := c.RollbackAutoSpottingTags(snapshots[snapshotSelect.SelectedIndex()]); err != nil {
+				dialog.ShowError(err, w)
+			} else {
+				dialog.ShowInformation("Rollback", "The AutoSpotting tags were restored.", w)
+			}
+			onDone()
+		}, w)
+}
+
+var applyResultHeaders = []string{"AutoScaling Group Name", "Status", "Tag changes", "Error"}
+
+// applyTagPlan applies the plan in the background and shows the result for
+// each ASG, allowing to re-run the ASGs that failed. Retries reuse the
+// snapshot taken by the first attempt.
+func applyTagPlan(w fyne.Window, c *core.Launcher, plan *core.TagPlan, retry bool, onDone func()) {
+	progress := dialog.NewCustomWithoutButtons("Applying the AutoSpotting configuration",
+		widget.NewProgressBarInfinite(), w)
+	progress.Show()
+
+	go func() {
+		apply := c.ApplyAutoSpottingTags
+		if retry {
+			apply = c.RetryAutoSpottingTags
+		}
+		results, err := apply(plan)
+		progress.Hide()
+		onDone()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		showApplyResults(w, c, plan, results, onDone)
+	}()
+}
+
+func showApplyResults(w fyne.Window, c *core.Launcher, plan *core.TagPlan, results []core.ApplyResult, onDone func()) {
+
+	count := map[core.ApplyStatus]int{}
+	for _, r := range results {
+		count[r.Status]++
+	}
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(results), len(applyResultHeaders)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			r := results[id.Row]
+			label.TextStyle = fyne.TextStyle{}
+			switch id.Col {
+			case 0:
+				label.SetText(*r.ASG.AutoScalingGroupName)
+			case 1:
+				label.TextStyle.Bold = r.Status == core.ApplyStatusFailed
+				label.SetText(string(r.Status))
+			case 2:
+				label.SetText(fmt.Sprintf("%d", len(r.Pending())))
+			case 3:
+				text := ""
+				if r.Err != nil {
+					text = r.Err.Error()
+				}
+				label.SetText(text)
+			}
+		})
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		header := o.(*widget.Label)
+		header.TextStyle.Bold = true
+		if id.Col >= 0 && id.Col < len(applyResultHeaders) {
+			header.SetText(applyResultHeaders[id.Col])
+		}
+	}
+	for i, width := range []float32{300, 100, 100, 500} {
+		table.SetColumnWidth(i, width)
+	}
+
+	message := "The configuration was persisted to your AutoScaling group tags. " +
+		"In order for it to be applied, \nyou need to install AutoSpotting " +
+		"from the AWS Marketplace using the link from the Welcome tab..."
+	summary := widget.NewLabel(fmt.Sprintf("%d applied, %d skipped and %d failed.\n\n%s",
+		count[core.ApplyStatusApplied], count[core.ApplyStatusSkipped], count[core.ApplyStatusFailed], message))
+
+	var d dialog.Dialog
+	buttons := container.NewHBox()
+	if count[core.ApplyStatusFailed] > 0 {
+		buttons.Add(widget.NewButton("Retry failed", func() {
+			d.Hide()
+			applyTagPlan(w, c, plan.Failed(results), true, onDone)
+		}))
+	}
+	buttons.Add(widget.NewButton("Close", func() { d.Hide() }))
+
+	d = dialog.NewCustomWithoutButtons("AutoSpotting configuration results",
+		container.NewBorder(summary, container.NewCenter(buttons), nil, nil, table), w)
+	d.Resize(fyne.NewSize(1000, 600))
+	d.Show()
+}